@@ -2,15 +2,83 @@ package config
 
 import (
 	"fmt"
+	"time"
 )
 
 type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
+	Wallet   WalletConfig
+	Log      LogConfig
+	Tracing  TracingConfig
+	Auth     AuthConfig
+}
+
+type AuthConfig struct {
+	// AdminKey grants the admin scope, which allows creating the first API
+	// keys. Empty disables it.
+	AdminKey string
+	// JWTSecret verifies HS256 bearer tokens and JWTPublicKeyFile, a PEM
+	// file, RS256 ones. Bearer tokens are rejected if neither is set.
+	JWTSecret        string
+	JWTPublicKeyFile string
+	// JWTIssuer and JWTAudience must match the token claims unless empty
+	JWTIssuer   string
+	JWTAudience string
+}
+
+type TracingConfig struct {
+	// Exporter is one of none, stdout or file
+	Exporter string
+	// File is where the file exporter appends spans
+	File string
+}
+
+type LogConfig struct {
+	// Level is one of debug, info, warn or error
+	Level string
 }
 
 type ServerConfig struct {
 	Port string
+	// RequestTimeout bounds how long a request may take, including lock waits
+	// and database queries
+	RequestTimeout time.Duration
+	// ShutdownDelay is how long the server keeps serving while reporting
+	// not-ready, so that load balancers stop routing to it before it drains
+	ShutdownDelay time.Duration
+	// DrainTimeout bounds how long in-flight requests may take to finish
+	DrainTimeout time.Duration
+	// IdempotencyKeyTTL is how long idempotency keys are kept, and
+	// IdempotencyCleanupInterval how often older ones are deleted
+	IdempotencyKeyTTL          time.Duration
+	IdempotencyCleanupInterval time.Duration
+}
+
+type WalletConfig struct {
+	// DefaultCurrency is used when a wallet is created without a currency
+	DefaultCurrency string
+	// RatesFile is a JSON file with static exchange rates, e.g. {"USD/EUR": "0.92"}
+	RatesFile string
+	QuoteTTL  time.Duration
+	// FeesFile is a JSON fee schedule, see the fees package; without it
+	// operations are free
+	FeesFile string
+	// LimitsFile holds the global and per tier wallet limits, see the limits
+	// package; without it only per-wallet overrides apply
+	LimitsFile string
+	// HoldDefaultTTL applies to holds created without an explicit expiry,
+	// HoldMaxTTL caps the expiry a client may ask for
+	HoldDefaultTTL time.Duration
+	HoldMaxTTL     time.Duration
+	// HoldExpiryInterval is how often expired holds are released
+	HoldExpiryInterval time.Duration
+	// Locker selects how operations on a wallet are serialised: "memory"
+	// within one process or "postgres" across all instances
+	Locker string
+	// FrozenDeposits lets frozen wallets receive deposits and incoming
+	// transfers
+	FrozenDeposits bool
 }
 
 type DatabaseConfig struct {
@@ -20,6 +88,8 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// MaxOpenConns caps the connection pool, 0 means unlimited
+	MaxOpenConns int
 }
 
 func Load() (*Config, error) {
@@ -27,7 +97,13 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: GetEnv(string(ServerPort), "8080"),
+			Port:           GetEnv(string(ServerPort), "8080"),
+			RequestTimeout: time.Duration(GetEnvAsInt(string(RequestTimeout), 10)) * time.Second,
+			ShutdownDelay:  time.Duration(GetEnvAsInt(string(ShutdownDelay), 5)) * time.Second,
+			DrainTimeout:   time.Duration(GetEnvAsInt(string(DrainTimeout), 20)) * time.Second,
+
+			IdempotencyKeyTTL:          time.Duration(GetEnvAsInt(string(IdempotencyKeyTTL), 24*60*60)) * time.Second,
+			IdempotencyCleanupInterval: time.Duration(GetEnvAsInt(string(IdempotencyCleanupInterval), 60*60)) * time.Second,
 		},
 		Database: DatabaseConfig{
 			Host:     GetEnv(string(DBHost), "localhost"),
@@ -36,6 +112,36 @@ func Load() (*Config, error) {
 			Password: GetEnv(string(DBPassword), "password"),
 			DBName:   GetEnv(string(DBName), "wallet_db"),
 			SSLMode:  GetEnv(string(DBSSLMode), "disable"),
+
+			MaxOpenConns: GetEnvAsInt(string(DBMaxOpenConns), 25),
+		},
+		Log: LogConfig{
+			Level: GetEnv(string(LogLevel), "info"),
+		},
+		Auth: AuthConfig{
+			AdminKey:         GetEnv(string(AuthAdminKey), ""),
+			JWTSecret:        GetEnv(string(JWTSecret), ""),
+			JWTPublicKeyFile: GetEnv(string(JWTPublicKeyFile), ""),
+			JWTIssuer:        GetEnv(string(JWTIssuer), ""),
+			JWTAudience:      GetEnv(string(JWTAudience), ""),
+		},
+		Tracing: TracingConfig{
+			Exporter: GetEnv(string(TracingExporter), "none"),
+			File:     GetEnv(string(TracingFile), "traces.json"),
+		},
+		Wallet: WalletConfig{
+			DefaultCurrency: GetEnv(string(DefaultCurrency), "USD"),
+			RatesFile:       GetEnv(string(FXRatesFile), ""),
+			QuoteTTL:        time.Duration(GetEnvAsInt(string(FXQuoteTTL), 30)) * time.Second,
+			FeesFile:        GetEnv(string(FeesFile), ""),
+			LimitsFile:      GetEnv(string(LimitsFile), ""),
+
+			HoldDefaultTTL:     time.Duration(GetEnvAsInt(string(HoldDefaultTTL), 7*24*60*60)) * time.Second,
+			HoldMaxTTL:         time.Duration(GetEnvAsInt(string(HoldMaxTTL), 30*24*60*60)) * time.Second,
+			HoldExpiryInterval: time.Duration(GetEnvAsInt(string(HoldExpiryInterval), 60)) * time.Second,
+
+			Locker:         GetEnv(string(WalletLocker), "memory"),
+			FrozenDeposits: GetEnvAsBool(string(WalletFrozenDeposits), true),
 		},
 	}
 
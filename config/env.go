@@ -1,7 +1,7 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
 	"strconv"
 
@@ -10,7 +10,7 @@ import (
 
 func LoadEnv() {
 	if err := godotenv.Load("config.env"); err != nil {
-		log.Printf("Warning: config.env file not found, using environment variables")
+		slog.Warn("config.env file not found, using environment variables")
 	}
 }
 
@@ -29,3 +29,12 @@ func GetEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func GetEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
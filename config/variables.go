@@ -3,11 +3,42 @@ package config
 type EnvVariable string
 
 const (
-	ServerPort EnvVariable = "SERVER_PORT"
-	DBHost     EnvVariable = "DB_HOST"
-	DBPort     EnvVariable = "DB_PORT"
-	DBUser     EnvVariable = "DB_USER"
-	DBPassword EnvVariable = "DB_PASSWORD"
-	DBName     EnvVariable = "DB_NAME"
-	DBSSLMode  EnvVariable = "DB_SSLMODE"
+	ServerPort     EnvVariable = "SERVER_PORT"
+	LogLevel       EnvVariable = "LOG_LEVEL"
+	RequestTimeout EnvVariable = "REQUEST_TIMEOUT_SECONDS"
+	ShutdownDelay  EnvVariable = "SHUTDOWN_DELAY_SECONDS"
+	DrainTimeout   EnvVariable = "SHUTDOWN_DRAIN_TIMEOUT_SECONDS"
+
+	IdempotencyKeyTTL          EnvVariable = "IDEMPOTENCY_KEY_TTL_SECONDS"
+	IdempotencyCleanupInterval EnvVariable = "IDEMPOTENCY_CLEANUP_INTERVAL_SECONDS"
+
+	DBHost         EnvVariable = "DB_HOST"
+	DBPort         EnvVariable = "DB_PORT"
+	DBUser         EnvVariable = "DB_USER"
+	DBPassword     EnvVariable = "DB_PASSWORD"
+	DBName         EnvVariable = "DB_NAME"
+	DBSSLMode      EnvVariable = "DB_SSLMODE"
+	DBMaxOpenConns EnvVariable = "DB_MAX_OPEN_CONNS"
+
+	DefaultCurrency EnvVariable = "DEFAULT_CURRENCY"
+	FXRatesFile     EnvVariable = "FX_RATES_FILE"
+	FXQuoteTTL      EnvVariable = "FX_QUOTE_TTL_SECONDS"
+	FeesFile        EnvVariable = "FEES_FILE"
+	LimitsFile      EnvVariable = "LIMITS_FILE"
+
+	HoldDefaultTTL     EnvVariable = "HOLD_DEFAULT_TTL_SECONDS"
+	HoldMaxTTL         EnvVariable = "HOLD_MAX_TTL_SECONDS"
+	HoldExpiryInterval EnvVariable = "HOLD_EXPIRY_INTERVAL_SECONDS"
+
+	WalletLocker         EnvVariable = "WALLET_LOCKER"
+	WalletFrozenDeposits EnvVariable = "WALLET_FROZEN_DEPOSITS"
+
+	AuthAdminKey     EnvVariable = "AUTH_ADMIN_KEY"
+	JWTSecret        EnvVariable = "JWT_HS256_SECRET"
+	JWTPublicKeyFile EnvVariable = "JWT_RS256_PUBLIC_KEY_FILE"
+	JWTIssuer        EnvVariable = "JWT_ISSUER"
+	JWTAudience      EnvVariable = "JWT_AUDIENCE"
+
+	TracingExporter EnvVariable = "TRACING_EXPORTER"
+	TracingFile     EnvVariable = "TRACING_FILE"
 )
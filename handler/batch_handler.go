@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+
+	"wallet_service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExecuteBatch responds 200 to best-effort batches whatever the outcome of
+// their items. A failed atomic batch gets the status its failing item would
+// have got on its own.
+func (h *WalletHandler) ExecuteBatch(c *gin.Context) {
+	var req models.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	result, err := h.walletService.ExecuteBatch(c.Request.Context(), req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Mode == models.BatchAtomic && result.Failed > 0 {
+		for _, item := range result.Results {
+			if item.Error != nil {
+				status = statusOf(item.Error.Code)
+			}
+		}
+	}
+	c.JSON(status, result)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatusClientClosedRequest is the non-standard status logged for requests
+// the client gave up on; the client never sees it.
+const StatusClientClosedRequest = 499
+
+// errorStatus maps domain error codes to HTTP statuses. Codes that are not
+// listed are treated as client errors.
+var errorStatus = map[apperrors.Code]int{
+	apperrors.CodeInvalidRequest:            http.StatusBadRequest,
+	apperrors.CodeInvalidAmount:             http.StatusBadRequest,
+	apperrors.CodeInvalidOperationType:      http.StatusBadRequest,
+	apperrors.CodeInvalidCursor:             http.StatusBadRequest,
+	apperrors.CodeInvalidCurrency:           http.StatusBadRequest,
+	apperrors.CodeCurrencyMismatch:          http.StatusUnprocessableEntity,
+	apperrors.CodeRateUnavailable:           http.StatusUnprocessableEntity,
+	apperrors.CodeQuoteNotFound:             http.StatusNotFound,
+	apperrors.CodeQuoteExpired:              http.StatusGone,
+	apperrors.CodeQuoteMismatch:             http.StatusUnprocessableEntity,
+	apperrors.CodeWalletNotFound:            http.StatusNotFound,
+	apperrors.CodeSourceWalletNotFound:      http.StatusNotFound,
+	apperrors.CodeDestinationWalletNotFound: http.StatusNotFound,
+	apperrors.CodeSameWallet:                http.StatusBadRequest,
+	apperrors.CodeInsufficientFunds:         http.StatusBadRequest,
+	apperrors.CodeWalletFrozen:              http.StatusConflict,
+	apperrors.CodeWalletClosed:              http.StatusConflict,
+	apperrors.CodeWalletNotEmpty:            http.StatusConflict,
+	apperrors.CodeInvalidStatusTransition:   http.StatusConflict,
+	apperrors.CodeLimitExceeded:             http.StatusUnprocessableEntity,
+	apperrors.CodeCreditLimitInUse:          http.StatusConflict,
+	apperrors.CodeTransactionNotFound:       http.StatusNotFound,
+	apperrors.CodeNotReversible:             http.StatusUnprocessableEntity,
+	apperrors.CodeReversalExceedsAmount:     http.StatusUnprocessableEntity,
+	apperrors.CodeHoldNotFound:              http.StatusNotFound,
+	apperrors.CodeHoldNotActive:             http.StatusConflict,
+	apperrors.CodeHoldExpired:               http.StatusConflict,
+	apperrors.CodeCaptureExceedsHold:        http.StatusUnprocessableEntity,
+	apperrors.CodeIdempotencyKeyReused:      http.StatusUnprocessableEntity,
+	apperrors.CodeIdempotencyKeyInProgress:  http.StatusConflict,
+	apperrors.CodeRequestTimeout:            http.StatusServiceUnavailable,
+	apperrors.CodeRequestCanceled:           StatusClientClosedRequest,
+	apperrors.CodeUnauthorized:              http.StatusUnauthorized,
+	apperrors.CodeForbidden:                 http.StatusForbidden,
+	apperrors.CodeAPIKeyNotFound:            http.StatusNotFound,
+	apperrors.CodeInternal:                  http.StatusInternalServerError,
+}
+
+// RespondError writes err as {"error": message, "code": code, "request_id": id}.
+// Errors caused by the request deadline or a client disconnect are reported
+// as such; other errors that are not domain errors are logged and reported as
+// a generic internal error.
+func RespondError(c *gin.Context, err error) {
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) {
+		appErr = contextError(c.Request.Context(), err)
+	}
+	if appErr == nil {
+		slog.ErrorContext(c.Request.Context(), "Internal error",
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"error", err,
+		)
+		appErr = apperrors.New(apperrors.CodeInternal, "internal server error")
+	}
+
+	body := gin.H{"error": appErr.Message, "code": appErr.Code}
+	if requestID := logging.RequestID(c.Request.Context()); requestID != "" {
+		body["request_id"] = requestID
+	}
+	c.AbortWithStatusJSON(statusOf(appErr.Code), body)
+}
+
+func statusOf(code apperrors.Code) int {
+	if status, ok := errorStatus[code]; ok {
+		return status
+	}
+	return http.StatusBadRequest
+}
+
+// contextError returns the domain error for err if it was caused by ctx
+// ending. The driver does not always wrap the context error, so the state of
+// ctx itself is checked as well.
+func contextError(ctx context.Context, err error) *apperrors.Error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return apperrors.ErrRequestTimeout
+	case errors.Is(err, context.Canceled), errors.Is(ctx.Err(), context.Canceled):
+		return apperrors.ErrRequestCanceled
+	default:
+		return nil
+	}
+}
+
+// respondBindError reports a request body that could not be decoded, keeping
+// the domain error when decoding failed because of e.g. an invalid amount.
+func respondBindError(c *gin.Context, err error) {
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		RespondError(c, appErr)
+		return
+	}
+	RespondError(c, invalidRequest("invalid request body"))
+}
+
+func invalidRequest(message string) error {
+	return apperrors.New(apperrors.CodeInvalidRequest, message)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WalletHandler) CreateHold(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_uuid"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid wallet UUID"))
+		return
+	}
+
+	var req models.HoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if req.Amount <= 0 {
+		RespondError(c, apperrors.ErrInvalidAmount)
+		return
+	}
+	if req.ExpiresInSeconds < 0 {
+		RespondError(c, invalidRequest("expiresInSeconds must not be negative"))
+		return
+	}
+
+	ttl := time.Duration(req.ExpiresInSeconds) * time.Second
+	hold, err := h.walletService.CreateHold(c.Request.Context(), walletID, req.Amount, req.Currency, ttl)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, hold)
+}
+
+func (h *WalletHandler) GetHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("hold_id"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid hold ID"))
+		return
+	}
+
+	hold, err := h.walletService.GetHold(c.Request.Context(), holdID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+func (h *WalletHandler) CaptureHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("hold_id"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid hold ID"))
+		return
+	}
+
+	var req models.CaptureRequest
+	// Without a body the whole hold is captured
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+
+	if req.Amount < 0 {
+		RespondError(c, apperrors.ErrInvalidAmount)
+		return
+	}
+
+	capture, err := h.walletService.CaptureHold(c.Request.Context(), holdID, req.Amount)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, capture)
+}
+
+func (h *WalletHandler) VoidHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("hold_id"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid hold ID"))
+		return
+	}
+
+	hold, err := h.walletService.VoidHold(c.Request.Context(), holdID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
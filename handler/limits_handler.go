@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"wallet_service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WalletHandler) GetWalletLimits(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_uuid"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid wallet UUID"))
+		return
+	}
+
+	limits, err := h.walletService.GetWalletLimits(c.Request.Context(), walletID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+func (h *WalletHandler) SetWalletLimits(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_uuid"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid wallet UUID"))
+		return
+	}
+
+	var req models.WalletLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	limits, err := h.walletService.SetWalletLimits(c.Request.Context(), walletID, req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
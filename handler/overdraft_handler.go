@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+
+	"wallet_service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WalletHandler) SetCreditLimit(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_uuid"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid wallet UUID"))
+		return
+	}
+
+	var req models.CreditLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	wallet, err := h.walletService.SetCreditLimit(c.Request.Context(), walletID, req.CreditLimit)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+func (h *WalletHandler) GetOverdraftPeriods(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_uuid"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid wallet UUID"))
+		return
+	}
+
+	periods, err := h.walletService.GetOverdraftPeriods(c.Request.Context(), walletID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"overdraft_periods": periods})
+}
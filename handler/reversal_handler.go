@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WalletHandler) ReverseTransaction(c *gin.Context) {
+	transactionID, err := uuid.Parse(c.Param("transaction_id"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid transaction ID"))
+		return
+	}
+
+	var req models.ReversalRequest
+	// Without a body the whole transaction is reversed
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+	if req.Amount < 0 {
+		RespondError(c, apperrors.ErrInvalidAmount)
+		return
+	}
+
+	reversal, err := h.walletService.ReverseTransaction(c.Request.Context(), transactionID, req.Amount)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, reversal)
+}
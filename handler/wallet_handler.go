@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"wallet_service/internal/apperrors"
 	"wallet_service/internal/models"
 	"wallet_service/internal/service"
 
@@ -12,12 +16,14 @@ import (
 )
 
 type WalletHandler struct {
-	walletService *service.WalletService
+	walletService   *service.WalletService
+	defaultCurrency models.Currency
 }
 
-func NewWalletHandler(walletService *service.WalletService) *WalletHandler {
+func NewWalletHandler(walletService *service.WalletService, defaultCurrency models.Currency) *WalletHandler {
 	return &WalletHandler{
-		walletService: walletService,
+		walletService:   walletService,
+		defaultCurrency: defaultCurrency,
 	}
 }
 
@@ -25,17 +31,13 @@ func (h *WalletHandler) GetWalletBalance(c *gin.Context) {
 	walletUUIDStr := c.Param("wallet_uuid")
 	walletID, err := uuid.Parse(walletUUIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid wallet UUID"})
+		RespondError(c, invalidRequest("invalid wallet UUID"))
 		return
 	}
 
-	wallet, err := h.walletService.GetWalletBalance(walletID)
+	wallet, err := h.walletService.GetWalletBalance(c.Request.Context(), walletID)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+		RespondError(c, err)
 		return
 	}
 
@@ -43,9 +45,21 @@ func (h *WalletHandler) GetWalletBalance(c *gin.Context) {
 }
 
 func (h *WalletHandler) CreateWallet(c *gin.Context) {
-	wallet, err := h.walletService.CreateWallet()
+	var req models.CreateWalletRequest
+	// The body is optional, older clients send none at all
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindError(c, err)
+			return
+		}
+	}
+	if req.Currency == "" {
+		req.Currency = h.defaultCurrency
+	}
+
+	wallet, err := h.walletService.CreateWallet(c.Request.Context(), req.Currency, req.OwnerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create wallet"})
+		RespondError(c, err)
 		return
 	}
 
@@ -55,33 +69,274 @@ func (h *WalletHandler) CreateWallet(c *gin.Context) {
 func (h *WalletHandler) PerformWalletOperation(c *gin.Context) {
 	var req models.WalletOperation
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		respondBindError(c, err)
 		return
 	}
 
 	if req.Amount <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be positive"})
+		RespondError(c, apperrors.ErrInvalidAmount)
 		return
 	}
 
 	if req.OperationType != models.DEPOSIT && req.OperationType != models.WITHDRAW {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid operation type"})
+		RespondError(c, apperrors.ErrInvalidOperationType)
 		return
 	}
 
-	err := h.walletService.PerformWalletOperation(req.WalletID, req.OperationType, req.Amount)
+	transaction, fee, err := h.walletService.PerformWalletOperation(c.Request.Context(), req.WalletID, req.OperationType, req.Amount, req.Currency)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Wallet not found"})
-			return
+		RespondError(c, err)
+		return
+	}
+
+	response := gin.H{"message": "Operation successful", "transaction": transaction}
+	if fee != nil {
+		response["fee"] = fee
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *WalletHandler) ListWallets(c *gin.Context) {
+	filter, err := parseWalletFilter(c)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	page, err := h.walletService.ListWallets(c.Request.Context(), filter)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseWalletFilter reads the listing query parameters: owner_id, status and
+// currency (both repeatable or comma separated), min_balance, max_balance, from, to
+// (RFC 3339, creation time), sort (created_at|balance), order (asc|desc),
+// limit and cursor.
+func parseWalletFilter(c *gin.Context) (models.WalletFilter, error) {
+	filter := models.WalletFilter{
+		SortBy: models.WalletSortByCreatedAt,
+		Order:  models.SortDesc,
+		Limit:  models.DefaultWalletPageSize,
+	}
+
+	if value := c.Query("owner_id"); value != "" {
+		filter.OwnerID = &value
+	}
+
+	for _, value := range c.QueryArray("status") {
+		for _, s := range strings.Split(value, ",") {
+			status := models.WalletStatus(strings.ToUpper(strings.TrimSpace(s)))
+			switch status {
+			case models.WalletActive, models.WalletFrozen, models.WalletClosed:
+				filter.Statuses = append(filter.Statuses, status)
+			default:
+				return filter, invalidRequest(fmt.Sprintf("invalid wallet status %q", s))
+			}
 		}
-		if strings.Contains(err.Error(), "insufficient funds") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient funds"})
-			return
+	}
+
+	for _, value := range c.QueryArray("currency") {
+		for _, code := range strings.Split(value, ",") {
+			currency, err := models.ParseCurrency(strings.TrimSpace(code))
+			if err != nil {
+				return filter, err
+			}
+			filter.Currencies = append(filter.Currencies, currency)
+		}
+	}
+
+	for param, target := range map[string]**models.Amount{"min_balance": &filter.MinBalance, "max_balance": &filter.MaxBalance} {
+		if value := c.Query(param); value != "" {
+			amount, err := models.ParseAmount(value)
+			if err != nil {
+				return filter, apperrors.New(apperrors.CodeInvalidAmount, "invalid "+param)
+			}
+			*target = &amount
+		}
+	}
+
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, invalidRequest("invalid " + param + ", expected RFC 3339 timestamp")
+			}
+			*target = &t
+		}
+	}
+
+	if value := c.Query("sort"); value != "" {
+		filter.SortBy = models.WalletSortField(value)
+		if filter.SortBy != models.WalletSortByCreatedAt && filter.SortBy != models.WalletSortByBalance {
+			return filter, invalidRequest("invalid sort field")
+		}
+	}
+
+	if value := c.Query("order"); value != "" {
+		filter.Order = models.SortOrder(strings.ToLower(value))
+		if filter.Order != models.SortAsc && filter.Order != models.SortDesc {
+			return filter, invalidRequest("invalid sort order")
+		}
+	}
+
+	var err error
+	filter.Cursor, err = parsePage(c, filter.SortBy, filter.Order, &filter.Limit, models.MaxWalletPageSize)
+	return filter, err
+}
+
+func (h *WalletHandler) GetTransactions(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_uuid"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid wallet UUID"))
+		return
+	}
+
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	page, err := h.walletService.GetTransactions(c.Request.Context(), walletID, filter)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseTransactionFilter reads the history query parameters:
+// type (repeatable or comma separated), min_amount, max_amount, from, to
+// (RFC 3339), sort (created_at|amount), order (asc|desc), limit and cursor.
+func parseTransactionFilter(c *gin.Context) (models.TransactionFilter, error) {
+	filter := models.TransactionFilter{
+		SortBy: models.SortByCreatedAt,
+		Order:  models.SortDesc,
+		Limit:  models.DefaultTransactionPageSize,
+	}
+
+	for _, value := range c.QueryArray("type") {
+		for _, t := range strings.Split(value, ",") {
+			operationType := models.OperationType(strings.ToUpper(strings.TrimSpace(t)))
+			switch operationType {
+			case models.DEPOSIT, models.WITHDRAW, models.TRANSFER_IN, models.TRANSFER_OUT, models.ADJUSTMENT, models.CAPTURE, models.REVERSAL, models.FEE:
+				filter.OperationTypes = append(filter.OperationTypes, operationType)
+			default:
+				return filter, apperrors.New(apperrors.CodeInvalidOperationType, fmt.Sprintf("invalid operation type %q", t))
+			}
+		}
+	}
+
+	for param, target := range map[string]**models.Amount{"min_amount": &filter.MinAmount, "max_amount": &filter.MaxAmount} {
+		if value := c.Query(param); value != "" {
+			amount, err := models.ParseAmount(value)
+			if err != nil {
+				return filter, apperrors.New(apperrors.CodeInvalidAmount, "invalid "+param)
+			}
+			*target = &amount
+		}
+	}
+
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, invalidRequest("invalid " + param + ", expected RFC 3339 timestamp")
+			}
+			*target = &t
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+
+	if value := c.Query("sort"); value != "" {
+		filter.SortBy = models.TransactionSortField(value)
+		if filter.SortBy != models.SortByCreatedAt && filter.SortBy != models.SortByAmount {
+			return filter, invalidRequest("invalid sort field")
+		}
+	}
+
+	if value := c.Query("order"); value != "" {
+		filter.Order = models.SortOrder(strings.ToLower(value))
+		if filter.Order != models.SortAsc && filter.Order != models.SortDesc {
+			return filter, invalidRequest("invalid sort order")
+		}
+	}
+
+	var err error
+	filter.Cursor, err = parsePage(c, filter.SortBy, filter.Order, &filter.Limit, models.MaxTransactionPageSize)
+	return filter, err
+}
+
+// parsePage reads the limit and cursor query parameters of a listing sorted
+// by sortBy in order. limit keeps its default unless the parameter is given;
+// a cursor is only valid for the sort it was issued for.
+func parsePage[F ~string](c *gin.Context, sortBy F, order models.SortOrder, limit *int, maxLimit int) (*models.Cursor[F], error) {
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 || n > maxLimit {
+			return nil, invalidRequest(fmt.Sprintf("limit must be between 1 and %d", maxLimit))
+		}
+		*limit = n
+	}
+
+	value := c.Query("cursor")
+	if value == "" {
+		return nil, nil
+	}
+	cursor, err := models.DecodeCursor[F](value)
+	if err != nil || cursor.SortBy != sortBy || cursor.Order != order {
+		return nil, models.ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+func (h *WalletHandler) Transfer(c *gin.Context) {
+	var req models.TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if req.FromWalletID == uuid.Nil || req.ToWalletID == uuid.Nil {
+		RespondError(c, invalidRequest("source and destination wallets are required"))
+		return
+	}
+
+	if req.Amount <= 0 {
+		RespondError(c, apperrors.ErrInvalidAmount)
+		return
+	}
+
+	transfer, err := h.walletService.Transfer(c.Request.Context(), req.FromWalletID, req.ToWalletID, req.Amount, req.Currency, req.QuoteID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *WalletHandler) CreateQuote(c *gin.Context) {
+	var req models.QuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	if req.FromWalletID == uuid.Nil || req.ToWalletID == uuid.Nil {
+		RespondError(c, invalidRequest("source and destination wallets are required"))
+		return
+	}
+
+	quote, err := h.walletService.CreateQuote(c.Request.Context(), req.FromWalletID, req.ToWalletID, req.Amount, req.Currency)
+	if err != nil {
+		RespondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Operation successful"})
+	c.JSON(http.StatusCreated, quote)
 }
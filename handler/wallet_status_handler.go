@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"wallet_service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WalletHandler) FreezeWallet(c *gin.Context) {
+	h.changeWalletStatus(c, h.walletService.FreezeWallet)
+}
+
+func (h *WalletHandler) UnfreezeWallet(c *gin.Context) {
+	h.changeWalletStatus(c, h.walletService.UnfreezeWallet)
+}
+
+func (h *WalletHandler) CloseWallet(c *gin.Context) {
+	h.changeWalletStatus(c, h.walletService.CloseWallet)
+}
+
+func (h *WalletHandler) changeWalletStatus(c *gin.Context, change func(context.Context, uuid.UUID, string) (*models.WalletStatusChange, error)) {
+	walletID, err := uuid.Parse(c.Param("wallet_uuid"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid wallet UUID"))
+		return
+	}
+
+	var req models.WalletStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindError(c, err)
+		return
+	}
+
+	statusChange, err := change(c.Request.Context(), walletID, req.Reason)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statusChange)
+}
+
+func (h *WalletHandler) GetWalletStatusChanges(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_uuid"))
+	if err != nil {
+		RespondError(c, invalidRequest("invalid wallet UUID"))
+		return
+	}
+
+	changes, err := h.walletService.GetWalletStatusChanges(c.Request.Context(), walletID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status_changes": changes})
+}
@@ -0,0 +1,110 @@
+// Package apperrors defines the domain errors returned by the repository and
+// service layers. Every error carries a stable machine-readable Code that is
+// exposed to API clients, so they never have to match on English messages.
+package apperrors
+
+import "errors"
+
+type Code string
+
+const (
+	CodeInvalidRequest            Code = "INVALID_REQUEST"
+	CodeInvalidAmount             Code = "INVALID_AMOUNT"
+	CodeInvalidOperationType      Code = "INVALID_OPERATION_TYPE"
+	CodeInvalidCursor             Code = "INVALID_CURSOR"
+	CodeInvalidCurrency           Code = "INVALID_CURRENCY"
+	CodeCurrencyMismatch          Code = "CURRENCY_MISMATCH"
+	CodeRateUnavailable           Code = "RATE_UNAVAILABLE"
+	CodeQuoteNotFound             Code = "QUOTE_NOT_FOUND"
+	CodeQuoteExpired              Code = "QUOTE_EXPIRED"
+	CodeQuoteMismatch             Code = "QUOTE_MISMATCH"
+	CodeWalletNotFound            Code = "WALLET_NOT_FOUND"
+	CodeSourceWalletNotFound      Code = "SOURCE_WALLET_NOT_FOUND"
+	CodeDestinationWalletNotFound Code = "DESTINATION_WALLET_NOT_FOUND"
+	CodeSameWallet                Code = "SAME_WALLET"
+	CodeInsufficientFunds         Code = "INSUFFICIENT_FUNDS"
+	CodeWalletFrozen              Code = "WALLET_FROZEN"
+	CodeWalletClosed              Code = "WALLET_CLOSED"
+	CodeWalletNotEmpty            Code = "WALLET_NOT_EMPTY"
+	CodeInvalidStatusTransition   Code = "INVALID_STATUS_TRANSITION"
+	CodeLimitExceeded             Code = "LIMIT_EXCEEDED"
+	CodeCreditLimitInUse          Code = "CREDIT_LIMIT_IN_USE"
+	CodeTransactionNotFound       Code = "TRANSACTION_NOT_FOUND"
+	CodeNotReversible             Code = "NOT_REVERSIBLE"
+	CodeReversalExceedsAmount     Code = "REVERSAL_EXCEEDS_AMOUNT"
+	CodeHoldNotFound              Code = "HOLD_NOT_FOUND"
+	CodeHoldNotActive             Code = "HOLD_NOT_ACTIVE"
+	CodeHoldExpired               Code = "HOLD_EXPIRED"
+	CodeCaptureExceedsHold        Code = "CAPTURE_EXCEEDS_HOLD"
+	CodeIdempotencyKeyReused      Code = "IDEMPOTENCY_KEY_REUSED"
+	CodeIdempotencyKeyInProgress  Code = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	CodeRequestTimeout            Code = "REQUEST_TIMEOUT"
+	CodeRequestCanceled           Code = "REQUEST_CANCELED"
+	CodeUnauthorized              Code = "UNAUTHORIZED"
+	CodeForbidden                 Code = "FORBIDDEN"
+	CodeAPIKeyNotFound            Code = "API_KEY_NOT_FOUND"
+	CodeInternal                  Code = "INTERNAL_ERROR"
+)
+
+// Error is a domain error. Sentinel values below are compared by identity,
+// so wrapping them with fmt.Errorf("...: %w", err) keeps errors.Is working.
+type Error struct {
+	Code    Code
+	Message string
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var (
+	ErrInvalidAmount             = New(CodeInvalidAmount, "amount must be positive")
+	ErrInvalidOperationType      = New(CodeInvalidOperationType, "invalid operation type")
+	ErrWalletNotFound            = New(CodeWalletNotFound, "wallet not found")
+	ErrSourceWalletNotFound      = New(CodeSourceWalletNotFound, "source wallet not found")
+	ErrDestinationWalletNotFound = New(CodeDestinationWalletNotFound, "destination wallet not found")
+	ErrSameWallet                = New(CodeSameWallet, "cannot transfer to the same wallet")
+	ErrInsufficientFunds         = New(CodeInsufficientFunds, "insufficient funds")
+	ErrCurrencyMismatch          = New(CodeCurrencyMismatch, "currency does not match the wallet currency")
+	ErrWalletFrozen              = New(CodeWalletFrozen, "wallet is frozen")
+	ErrWalletClosed              = New(CodeWalletClosed, "wallet is closed")
+	ErrWalletNotEmpty            = New(CodeWalletNotEmpty, "wallet must have a zero balance and no active holds to be closed")
+	ErrInvalidStatusTransition   = New(CodeInvalidStatusTransition, "wallet status cannot be changed this way")
+	ErrOperationLimitExceeded    = New(CodeLimitExceeded, "amount exceeds the single operation limit")
+	ErrDailyLimitExceeded        = New(CodeLimitExceeded, "operation exceeds the daily limit")
+	ErrMonthlyLimitExceeded      = New(CodeLimitExceeded, "operation exceeds the monthly limit")
+	ErrMaxBalanceExceeded        = New(CodeLimitExceeded, "operation would exceed the maximum balance")
+	ErrMinBalanceExceeded        = New(CodeLimitExceeded, "operation would take the available balance below the minimum")
+	ErrCreditLimitInUse          = New(CodeCreditLimitInUse, "credit limit cannot be lowered below the credit in use")
+	ErrQuoteNotFound             = New(CodeQuoteNotFound, "quote not found")
+	ErrQuoteExpired              = New(CodeQuoteExpired, "quote has expired or was already used")
+	ErrQuoteMismatch             = New(CodeQuoteMismatch, "transfer does not match the quote")
+	ErrTransactionNotFound       = New(CodeTransactionNotFound, "transaction not found")
+	ErrNotReversible             = New(CodeNotReversible, "transaction cannot be reversed")
+	ErrReversalExceedsAmount     = New(CodeReversalExceedsAmount, "reversal exceeds the amount left to reverse")
+	ErrHoldNotFound              = New(CodeHoldNotFound, "hold not found")
+	ErrHoldNotActive             = New(CodeHoldNotActive, "hold is no longer active")
+	ErrHoldExpired               = New(CodeHoldExpired, "hold has expired")
+	ErrCaptureExceedsHold        = New(CodeCaptureExceedsHold, "capture amount exceeds the held amount")
+	ErrIdempotencyKeyReused      = New(CodeIdempotencyKeyReused, "idempotency key was already used with a different request")
+	ErrIdempotencyKeyInProgress  = New(CodeIdempotencyKeyInProgress, "a request with this idempotency key is still in progress")
+	ErrRequestTimeout            = New(CodeRequestTimeout, "request timed out")
+	ErrRequestCanceled           = New(CodeRequestCanceled, "request was canceled by the client")
+	ErrUnauthorized              = New(CodeUnauthorized, "missing or invalid credentials")
+	ErrForbidden                 = New(CodeForbidden, "credentials do not grant access to this resource")
+	ErrAPIKeyNotFound            = New(CodeAPIKeyNotFound, "API key not found")
+)
+
+// CodeOf returns the code of the first domain error in err's chain, or
+// CodeInternal if there is none.
+func CodeOf(err error) Code {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return CodeInternal
+}
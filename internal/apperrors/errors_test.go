@@ -0,0 +1,23 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	wrapped := fmt.Errorf("transfer failed: %w", ErrInsufficientFunds)
+
+	if code := CodeOf(wrapped); code != CodeInsufficientFunds {
+		t.Errorf("Expected code %v, got %v", CodeInsufficientFunds, code)
+	}
+
+	if !errors.Is(wrapped, ErrInsufficientFunds) {
+		t.Error("Expected wrapped error to match ErrInsufficientFunds")
+	}
+
+	if code := CodeOf(errors.New("connection reset")); code != CodeInternal {
+		t.Errorf("Expected code %v for plain error, got %v", CodeInternal, code)
+	}
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGenerateKey(t *testing.T) {
+	a, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	b, _ := GenerateKey()
+
+	if !strings.HasPrefix(a, KeyPrefix) || a == b {
+		t.Errorf("Expected distinct prefixed keys, got %q and %q", a, b)
+	}
+	if HashKey(a) == HashKey(b) || HashKey(a) != HashKey(a) {
+		t.Error("Expected hashes to be deterministic and distinct per key")
+	}
+	if got := KeyDisplayPrefix(a); len(got) != keyDisplayLength || !strings.HasPrefix(a, got) {
+		t.Errorf("Unexpected display prefix %q", got)
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	if scope, err := ParseScope("wallets:read"); err != nil || scope != ScopeWalletsRead {
+		t.Errorf("Expected wallets:read, got %q, %v", scope, err)
+	}
+	if _, err := ParseScope("wallets:delete"); err == nil {
+		t.Error("Expected error for unknown scope")
+	}
+}
+
+func TestPrincipalContext(t *testing.T) {
+	if PrincipalFrom(context.Background()) != nil {
+		t.Error("Expected no principal in empty context")
+	}
+
+	principal := &Principal{ID: "key-1", Scopes: []Scope{ScopeWalletsRead}}
+	ctx := WithPrincipal(context.Background(), principal)
+	if PrincipalFrom(ctx) != principal {
+		t.Error("Expected principal from context")
+	}
+	if !principal.HasScope(ScopeWalletsRead) || principal.HasScope(ScopeAdmin) {
+		t.Error("Unexpected scopes")
+	}
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strings"
+
+	"wallet_service/internal/apperrors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// userScopes are granted to tokens that carry no scope claim.
+var userScopes = []Scope{ScopeWalletsRead, ScopeWalletsWrite, ScopeTransfersWrite}
+
+// claims are the claims read from bearer tokens. Scope is space separated
+// as in OAuth 2.0.
+type claims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// JWTVerifier authenticates bearer tokens signed with HS256 and/or RS256
+// using locally configured keys.
+type JWTVerifier struct {
+	hmacSecret []byte
+	rsaKey     *rsa.PublicKey
+	parser     *jwt.Parser
+}
+
+// NewJWTVerifier returns a verifier for tokens signed with hmacSecret
+// (HS256) or the RSA key in rsaPublicKeyPEM (RS256); at least one of them is
+// required. Non-empty issuer and audience must match the token claims.
+func NewJWTVerifier(hmacSecret, rsaPublicKeyPEM []byte, issuer, audience string) (*JWTVerifier, error) {
+	v := &JWTVerifier{hmacSecret: hmacSecret}
+	var methods []string
+	if len(hmacSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(rsaPublicKeyPEM) > 0 {
+		key, err := jwt.ParseRSAPublicKeyFromPEM(rsaPublicKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA public key: %w", err)
+		}
+		v.rsaKey = key
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("JWT verification requires an HS256 secret or an RS256 public key")
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Authenticate verifies token and returns its principal. The subject claim
+// is the owner the token acts for.
+func (v *JWTVerifier) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	var c claims
+	if _, err := v.parser.ParseWithClaims(token, &c, v.key); err != nil {
+		return nil, apperrors.ErrUnauthorized
+	}
+	if c.Subject == "" {
+		return nil, apperrors.ErrUnauthorized
+	}
+
+	principal := &Principal{ID: "user:" + c.Subject, Name: c.Subject, OwnerID: c.Subject, Scopes: userScopes}
+	if c.Scope != "" {
+		principal.Scopes = nil
+		for _, s := range strings.Fields(c.Scope) {
+			// Scopes of other services may share the token
+			if scope, err := ParseScope(s); err == nil {
+				principal.Scopes = append(principal.Scopes, scope)
+			}
+		}
+	}
+	for _, r := range c.Roles {
+		if role, err := ParseRole(r); err == nil {
+			principal.Roles = append(principal.Roles, role)
+		}
+	}
+	return principal, nil
+}
+
+// key returns the verification key for the algorithm of token. The parser
+// has already rejected algorithms that are not configured.
+func (v *JWTVerifier) key(token *jwt.Token) (any, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return v.hmacSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		return v.rsaKey, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+}
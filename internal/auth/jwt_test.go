@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet_service/internal/apperrors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return token
+}
+
+func TestJWTVerifier_HS256(t *testing.T) {
+	secret := []byte("test-secret")
+	verifier, err := NewJWTVerifier(secret, nil, "wallet-issuer", "")
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	token := signToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{
+		"sub": "customer-1", "iss": "wallet-issuer", "exp": exp, "scope": "wallets:read other:scope",
+	})
+	principal, err := verifier.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Expected valid token, got %v", err)
+	}
+	if principal.OwnerID != "customer-1" || !principal.HasScope(ScopeWalletsRead) || principal.HasScope(ScopeWalletsWrite) {
+		t.Errorf("Unexpected principal %+v", principal)
+	}
+	if principal.IsAdmin() {
+		t.Error("Expected a regular user")
+	}
+
+	rejected := map[string]string{
+		"wrong secret": signToken(t, jwt.SigningMethodHS256, []byte("other"), jwt.MapClaims{"sub": "c", "iss": "wallet-issuer", "exp": exp}),
+		"expired":      signToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "c", "iss": "wallet-issuer", "exp": time.Now().Add(-time.Minute).Unix()}),
+		"no expiry":    signToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "c", "iss": "wallet-issuer"}),
+		"wrong issuer": signToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"sub": "c", "iss": "other", "exp": exp}),
+		"no subject":   signToken(t, jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "wallet-issuer", "exp": exp}),
+		"alg none":     signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"sub": "c", "iss": "wallet-issuer", "exp": exp}),
+		"garbage":      "not-a-token",
+	}
+	for name, token := range rejected {
+		if _, err := verifier.Authenticate(context.Background(), token); !errors.Is(err, apperrors.ErrUnauthorized) {
+			t.Errorf("%s: expected unauthorized, got %v", name, err)
+		}
+	}
+}
+
+func TestJWTVerifier_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	verifier, err := NewJWTVerifier(nil, publicPEM, "", "wallet-api")
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	token := signToken(t, jwt.SigningMethodRS256, key, jwt.MapClaims{
+		"sub": "backoffice", "aud": "wallet-api", "exp": time.Now().Add(time.Hour).Unix(), "roles": []string{"admin"},
+	})
+	principal, err := verifier.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Expected valid token, got %v", err)
+	}
+	if !principal.IsAdmin() || !principal.HasScope(ScopeTransfersWrite) {
+		t.Errorf("Expected admin with default scopes, got %+v", principal)
+	}
+
+	// An HS256 token signed with the public key must not pass as RS256
+	forged := signToken(t, jwt.SigningMethodHS256, publicPEM, jwt.MapClaims{
+		"sub": "backoffice", "aud": "wallet-api", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := verifier.Authenticate(context.Background(), forged); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Errorf("Expected forged token to be rejected, got %v", err)
+	}
+}
+
+func TestPrincipal_CanAccess(t *testing.T) {
+	owner, other := "customer-1", "customer-2"
+	user := &Principal{OwnerID: owner}
+	admin := &Principal{Roles: []Role{RoleAdmin}}
+	service := &Principal{}
+
+	if !user.CanAccess(&owner) || user.CanAccess(&other) || user.CanAccess(nil) {
+		t.Error("Expected users to access only their own wallets")
+	}
+	if !admin.CanAccess(&other) || !admin.CanAccess(nil) {
+		t.Error("Expected admins to access any wallet")
+	}
+	if service.CanAccess(nil) {
+		t.Error("Expected principals without owner to access no wallet")
+	}
+}
@@ -0,0 +1,53 @@
+package exchange
+
+import (
+	"math/big"
+	"strings"
+
+	"wallet_service/internal/models"
+)
+
+// Conversion is the result of converting an amount at a given rate.
+// Rounding is the destination amount minus the exact converted value, in
+// major units of the destination currency.
+type Conversion struct {
+	Amount   models.Amount
+	Rate     *big.Rat
+	Rounding *big.Rat
+}
+
+// Convert converts amount at rate and rounds the result half-up to the minor
+// unit of the to currency.
+func Convert(amount models.Amount, rate *big.Rat, to models.Currency) Conversion {
+	// exact value in major units
+	exact := new(big.Rat).Mul(big.NewRat(amount.MinorUnits(), pow10(models.AmountScale)), rate)
+
+	// value in minor units of the destination currency, rounded half-up
+	unit := pow10(to.Exponent())
+	scaled := new(big.Rat).Mul(exact, big.NewRat(unit, 1))
+	scaled.Add(scaled, big.NewRat(1, 2))
+	rounded := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+
+	minor := rounded.Int64() * pow10(models.AmountScale-to.Exponent())
+	converted := models.Amount(minor)
+
+	rounding := new(big.Rat).Sub(big.NewRat(minor, pow10(models.AmountScale)), exact)
+	return Conversion{Amount: converted, Rate: rate, Rounding: rounding}
+}
+
+// FormatDecimal renders r as an exact decimal string. Rates and amounts have
+// power-of-ten denominators, so their products need at most
+// MaxRateScale+AmountScale fractional digits.
+func FormatDecimal(r *big.Rat) string {
+	s := r.FloatString(MaxRateScale + models.AmountScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func pow10(n int) int64 {
+	result := int64(1)
+	for i := 0; i < n; i++ {
+		result *= 10
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package exchange
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"wallet_service/internal/models"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		amount   models.Amount
+		rate     string
+		to       models.Currency
+		expected models.Amount
+		rounding string
+	}{
+		{models.NewAmount(100, 0), "0.92", "EUR", models.NewAmount(92, 0), "0"},
+		{models.NewAmount(10, 1), "0.923456", "EUR", models.NewAmount(9, 24), "-0.00379456"},
+		{models.NewAmount(1, 0), "0.005", "USD", models.NewAmount(0, 1), "0.005"},
+		{models.NewAmount(10, 0), "151.37", "JPY", models.NewAmount(1514, 0), "0.3"},
+	}
+
+	for _, tt := range tests {
+		rate, err := ParseRate(tt.rate)
+		if err != nil {
+			t.Fatalf("Failed to parse rate %q: %v", tt.rate, err)
+		}
+
+		conversion := Convert(tt.amount, rate, tt.to)
+		if conversion.Amount != tt.expected {
+			t.Errorf("Convert(%v, %s, %s) = %v, expected %v", tt.amount, tt.rate, tt.to, conversion.Amount, tt.expected)
+		}
+		if got := FormatDecimal(conversion.Rounding); got != tt.rounding {
+			t.Errorf("Convert(%v, %s, %s) rounding = %s, expected %s", tt.amount, tt.rate, tt.to, got, tt.rounding)
+		}
+	}
+}
+
+func TestStaticRateProvider(t *testing.T) {
+	provider, err := NewStaticRateProvider(map[string]string{"USD/EUR": "0.8"})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	if err != nil || rate.Cmp(big.NewRat(4, 5)) != 0 {
+		t.Errorf("Expected USD/EUR rate 0.8, got %v (%v)", rate, err)
+	}
+
+	rate, err = provider.Rate(context.Background(), "EUR", "USD")
+	if err != nil || rate.Cmp(big.NewRat(5, 4)) != 0 {
+		t.Errorf("Expected derived EUR/USD rate 1.25, got %v (%v)", rate, err)
+	}
+
+	if _, err := provider.Rate(context.Background(), "USD", "JPY"); err != ErrRateUnavailable {
+		t.Errorf("Expected rate unavailable error, got %v", err)
+	}
+}
+
+func TestParseRate_Invalid(t *testing.T) {
+	for _, value := range []string{"0", "-1", "abc", "1/3", "1e2", "0.1234567890123"} {
+		if _, err := ParseRate(value); err == nil {
+			t.Errorf("ParseRate(%q) expected error, got nil", value)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package exchange provides exchange rates and exact currency conversion for
+// cross-currency transfers.
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+)
+
+// MaxRateScale is the maximum number of fractional digits of a rate.
+const MaxRateScale = 12
+
+var ErrRateUnavailable = apperrors.New(apperrors.CodeRateUnavailable, "exchange rate is not available")
+
+// RateProvider returns how many units of to one unit of from buys.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to models.Currency) (*big.Rat, error)
+}
+
+type pair struct {
+	from, to models.Currency
+}
+
+// StaticRateProvider serves a fixed set of rates. It is meant for tests and
+// local runs; the inverse of a configured pair is derived automatically.
+type StaticRateProvider struct {
+	rates map[pair]*big.Rat
+}
+
+// NewStaticRateProvider builds a provider from pairs such as
+// {"USD/EUR": "0.92"}.
+func NewStaticRateProvider(rates map[string]string) (*StaticRateProvider, error) {
+	p := &StaticRateProvider{rates: make(map[pair]*big.Rat, len(rates))}
+	for key, value := range rates {
+		fromCode, toCode, ok := strings.Cut(key, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid currency pair %q, expected FROM/TO", key)
+		}
+		from, err := models.ParseCurrency(fromCode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid currency pair %q: %w", key, err)
+		}
+		to, err := models.ParseCurrency(toCode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid currency pair %q: %w", key, err)
+		}
+		rate, err := ParseRate(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %q: %w", key, err)
+		}
+		p.rates[pair{from, to}] = rate
+	}
+	return p, nil
+}
+
+// LoadStaticRateProvider reads rates from a JSON file mapping pairs to
+// decimal strings. An empty path yields a provider without any rates.
+func LoadStaticRateProvider(path string) (*StaticRateProvider, error) {
+	if path == "" {
+		return NewStaticRateProvider(nil)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var rates map[string]string
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file: %w", err)
+	}
+	return NewStaticRateProvider(rates)
+}
+
+func (p *StaticRateProvider) Rate(ctx context.Context, from, to models.Currency) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+	if rate, ok := p.rates[pair{from, to}]; ok {
+		return new(big.Rat).Set(rate), nil
+	}
+	if rate, ok := p.rates[pair{to, from}]; ok {
+		inverse := new(big.Rat).Inv(rate)
+		// Keep derived rates within the precision the ledger stores
+		return ParseRate(inverse.FloatString(MaxRateScale))
+	}
+	return nil, ErrRateUnavailable
+}
+
+// ParseRate parses a positive decimal rate with at most MaxRateScale
+// fractional digits.
+func ParseRate(s string) (*big.Rat, error) {
+	if _, frac, ok := strings.Cut(s, "."); ok && len(frac) > MaxRateScale {
+		return nil, fmt.Errorf("rate %q has more than %d fractional digits", s, MaxRateScale)
+	}
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, "eE/") {
+		return nil, fmt.Errorf("invalid rate %q", s)
+	}
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("rate must be positive")
+	}
+	return rate, nil
+}
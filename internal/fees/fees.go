@@ -0,0 +1,166 @@
+// Package fees evaluates the fee schedule of withdrawals and transfers.
+package fees
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"os"
+	"strings"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// errNoRevenueWallet is only returned by a schedule NewSchedule did not
+// validate.
+var errNoRevenueWallet = apperrors.New(apperrors.CodeInternal, "no revenue wallet for fees")
+
+// Operation is an operation fees can be charged on.
+type Operation string
+
+const (
+	Withdrawal Operation = "WITHDRAW"
+	Transfer   Operation = "TRANSFER"
+)
+
+// Rule prices one operation type, in one currency or, when Currency is
+// empty, in every currency with a revenue wallet but without a rule of its
+// own. The fee is Flat plus
+// Percent of the amount, rounded half-up to the minor unit and then raised
+// to Min and capped at Max; a zero Max means no cap.
+type Rule struct {
+	Operation Operation       `json:"operation"`
+	Currency  models.Currency `json:"currency,omitempty"`
+	Flat      models.Amount   `json:"flat"`
+	Percent   string          `json:"percent,omitempty"`
+	Min       models.Amount   `json:"min"`
+	Max       models.Amount   `json:"max"`
+}
+
+// Config is the fee schedule file: the rules and, per currency, the wallet
+// fees in that currency are credited to.
+type Config struct {
+	RevenueWallets map[models.Currency]uuid.UUID `json:"revenue_wallets"`
+	Rules          []Rule                        `json:"rules"`
+}
+
+type key struct {
+	operation Operation
+	currency  models.Currency
+}
+
+type rule struct {
+	Rule
+	percent *big.Rat
+}
+
+type Schedule struct {
+	rules          map[key]rule
+	revenueWallets map[models.Currency]uuid.UUID
+}
+
+// NewSchedule validates cfg. A rule for a specific currency needs a revenue
+// wallet in that currency.
+func NewSchedule(cfg Config) (*Schedule, error) {
+	s := &Schedule{rules: make(map[key]rule, len(cfg.Rules)), revenueWallets: cfg.RevenueWallets}
+	for i, r := range cfg.Rules {
+		if r.Operation != Withdrawal && r.Operation != Transfer {
+			return nil, fmt.Errorf("rule %d: invalid operation %q", i, r.Operation)
+		}
+		if r.Currency != "" {
+			currency, err := models.ParseCurrency(string(r.Currency))
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %w", i, err)
+			}
+			if _, ok := cfg.RevenueWallets[currency]; !ok {
+				return nil, fmt.Errorf("rule %d: no revenue wallet for %s", i, currency)
+			}
+			r.Currency = currency
+		}
+		if r.Flat < 0 || r.Min < 0 || r.Max < 0 || (r.Max > 0 && r.Min > r.Max) {
+			return nil, fmt.Errorf("rule %d: flat, min and max must not be negative and min must not exceed max", i)
+		}
+
+		percent := new(big.Rat)
+		if r.Percent != "" {
+			p, ok := percent.SetString(r.Percent)
+			if !ok || strings.ContainsAny(r.Percent, "eE/") || p.Sign() < 0 {
+				return nil, fmt.Errorf("rule %d: invalid percent %q", i, r.Percent)
+			}
+		}
+
+		k := key{r.Operation, r.Currency}
+		if _, ok := s.rules[k]; ok {
+			return nil, fmt.Errorf("rule %d: duplicate rule for %s %s", i, r.Operation, r.Currency)
+		}
+		s.rules[k] = rule{Rule: r, percent: percent}
+	}
+	return s, nil
+}
+
+// Load reads a fee schedule from a JSON file. An empty path yields a schedule
+// without any fees.
+func Load(path string) (*Schedule, error) {
+	if path == "" {
+		return NewSchedule(Config{})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fee schedule: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse fee schedule: %w", err)
+	}
+	return NewSchedule(cfg)
+}
+
+// RevenueWallets returns the wallet fees are credited to per currency.
+func (s *Schedule) RevenueWallets() map[models.Currency]uuid.UUID {
+	return maps.Clone(s.revenueWallets)
+}
+
+// Fee returns the fee of the operation, or nil if it is free.
+func (s *Schedule) Fee(operation Operation, amount models.Amount, currency models.Currency) (*models.Fee, error) {
+	revenueWalletID, hasRevenueWallet := s.revenueWallets[currency]
+	r, ok := s.rules[key{operation, currency}]
+	if !ok {
+		// Fees nobody could be credited with are not charged
+		if r, ok = s.rules[key{operation, ""}]; !ok || !hasRevenueWallet {
+			return nil, nil
+		}
+	}
+
+	fee := r.Flat + percentOf(amount, r.percent, currency)
+	fee = max(fee, r.Min)
+	if r.Max > 0 {
+		fee = min(fee, r.Max)
+	}
+	if fee == 0 {
+		return nil, nil
+	}
+
+	if !hasRevenueWallet {
+		return nil, errNoRevenueWallet
+	}
+	return &models.Fee{Amount: fee, Currency: currency, RevenueWalletID: revenueWalletID}, nil
+}
+
+// percentOf returns percent % of amount rounded half-up to the minor unit of
+// currency.
+func percentOf(amount models.Amount, percent *big.Rat, currency models.Currency) models.Amount {
+	unit := int64(1)
+	for i := currency.Exponent(); i < models.AmountScale; i++ {
+		unit *= 10
+	}
+	scaled := new(big.Rat).Mul(big.NewRat(amount.MinorUnits(), 100*unit), percent)
+	scaled.Add(scaled, big.NewRat(1, 2))
+	rounded := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+	return models.Amount(rounded.Int64() * unit)
+}
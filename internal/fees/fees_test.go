@@ -0,0 +1,75 @@
+package fees
+
+import (
+	"testing"
+
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+func TestSchedule_Fee(t *testing.T) {
+	usdWallet, jpyWallet := uuid.New(), uuid.New()
+	schedule, err := NewSchedule(Config{
+		RevenueWallets: map[models.Currency]uuid.UUID{"USD": usdWallet, "JPY": jpyWallet},
+		Rules: []Rule{
+			{Operation: Withdrawal, Currency: "USD", Flat: models.NewAmount(0, 50), Percent: "1.5", Max: models.NewAmount(20, 0)},
+			{Operation: Withdrawal, Percent: "2", Min: models.NewAmount(1, 0)},
+			{Operation: Transfer, Currency: "USD", Flat: models.NewAmount(0, 10)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build schedule: %v", err)
+	}
+
+	tests := []struct {
+		operation Operation
+		amount    models.Amount
+		currency  models.Currency
+		expected  models.Amount
+	}{
+		{Withdrawal, models.NewAmount(100, 0), "USD", models.NewAmount(2, 0)},
+		{Withdrawal, models.NewAmount(0, 33), "USD", models.NewAmount(0, 50)},
+		{Withdrawal, models.NewAmount(10000, 0), "USD", models.NewAmount(20, 0)},
+		{Withdrawal, models.NewAmount(10, 0), "JPY", models.NewAmount(1, 0)},
+		{Withdrawal, models.NewAmount(1025, 0), "JPY", models.NewAmount(21, 0)},
+		{Transfer, models.NewAmount(100, 0), "USD", models.NewAmount(0, 10)},
+		{Transfer, models.NewAmount(100, 0), "JPY", 0},
+	}
+
+	for _, tt := range tests {
+		fee, err := schedule.Fee(tt.operation, tt.amount, tt.currency)
+		if err != nil {
+			t.Fatalf("Fee(%s, %v, %s) failed: %v", tt.operation, tt.amount, tt.currency, err)
+		}
+		var got models.Amount
+		if fee != nil {
+			got = fee.Amount
+		}
+		if got != tt.expected {
+			t.Errorf("Fee(%s, %v, %s) = %v, expected %v", tt.operation, tt.amount, tt.currency, got, tt.expected)
+		}
+	}
+
+	// The catch-all withdrawal rule skips EUR, which has no revenue wallet
+	if fee, err := schedule.Fee(Withdrawal, models.NewAmount(10, 0), "EUR"); fee != nil || err != nil {
+		t.Errorf("Expected no fee for a currency without a revenue wallet, got %+v, %v", fee, err)
+	}
+}
+
+func TestNewSchedule_Invalid(t *testing.T) {
+	tests := []Rule{
+		{Operation: "DEPOSIT", Flat: models.NewAmount(1, 0)},
+		{Operation: Withdrawal, Currency: "EUR", Flat: models.NewAmount(1, 0)},
+		{Operation: Withdrawal, Percent: "-1"},
+		{Operation: Withdrawal, Percent: "1e2"},
+		{Operation: Withdrawal, Min: models.NewAmount(5, 0), Max: models.NewAmount(1, 0)},
+	}
+
+	for _, r := range tests {
+		_, err := NewSchedule(Config{RevenueWallets: map[models.Currency]uuid.UUID{"USD": uuid.New()}, Rules: []Rule{r}})
+		if err == nil {
+			t.Errorf("Expected rule %+v to be rejected", r)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package limits resolves the limits that apply to a wallet from the global
+// defaults, the wallet's tier and the wallet's own overrides.
+package limits
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"wallet_service/internal/models"
+)
+
+// Config is the limits file. Tiers override the defaults field by field, so
+// a tier only lists the limits that differ.
+type Config struct {
+	Default models.WalletLimits            `json:"default"`
+	Tiers   map[string]models.WalletLimits `json:"tiers"`
+}
+
+type Policy struct {
+	defaults models.WalletLimits
+	tiers    map[string]models.WalletLimits
+}
+
+// NewPolicy validates cfg.
+func NewPolicy(cfg Config) (*Policy, error) {
+	if err := cfg.Default.Validate(); err != nil {
+		return nil, fmt.Errorf("default limits: %w", err)
+	}
+	p := &Policy{defaults: cfg.Default, tiers: make(map[string]models.WalletLimits, len(cfg.Tiers))}
+	for tier, limits := range cfg.Tiers {
+		if tier == "" {
+			return nil, fmt.Errorf("tier name must not be empty")
+		}
+		limits = cfg.Default.Override(limits)
+		if err := limits.Validate(); err != nil {
+			return nil, fmt.Errorf("tier %s: %w", tier, err)
+		}
+		p.tiers[tier] = limits
+	}
+	return p, nil
+}
+
+// Load reads the limits from a JSON file. An empty path yields a policy
+// without global or tier limits; per-wallet overrides still apply.
+func Load(path string) (*Policy, error) {
+	if path == "" {
+		return NewPolicy(Config{})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read limits: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse limits: %w", err)
+	}
+	return NewPolicy(cfg)
+}
+
+// HasTier reports whether wallets can be assigned tier.
+func (p *Policy) HasTier(tier string) bool {
+	if tier == models.DefaultWalletTier {
+		return true
+	}
+	if p == nil {
+		return false
+	}
+	_, ok := p.tiers[tier]
+	return ok
+}
+
+// For returns the limits of a wallet in tier with the given overrides. A nil
+// policy only applies the overrides.
+func (p *Policy) For(tier string, overrides models.WalletLimits) models.WalletLimits {
+	if p == nil {
+		return overrides
+	}
+	limits, ok := p.tiers[tier]
+	if !ok {
+		limits = p.defaults
+	}
+	return limits.Override(overrides)
+}
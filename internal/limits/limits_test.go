@@ -0,0 +1,67 @@
+package limits
+
+import (
+	"testing"
+
+	"wallet_service/internal/models"
+)
+
+func amount(major int64) *models.Amount {
+	a := models.NewAmount(major, 0)
+	return &a
+}
+
+func TestPolicy_For(t *testing.T) {
+	policy, err := NewPolicy(Config{
+		Default: models.WalletLimits{MaxOperation: amount(1000), DailyWithdrawal: amount(2000)},
+		Tiers: map[string]models.WalletLimits{
+			"premium": {MaxOperation: amount(10000)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build policy: %v", err)
+	}
+
+	standard := policy.For(models.DefaultWalletTier, models.WalletLimits{})
+	if *standard.MaxOperation != models.NewAmount(1000, 0) || *standard.DailyWithdrawal != models.NewAmount(2000, 0) {
+		t.Errorf("Unexpected standard limits: %+v", standard)
+	}
+
+	// Tiers inherit the defaults they do not override
+	premium := policy.For("premium", models.WalletLimits{})
+	if *premium.MaxOperation != models.NewAmount(10000, 0) || *premium.DailyWithdrawal != models.NewAmount(2000, 0) {
+		t.Errorf("Unexpected premium limits: %+v", premium)
+	}
+
+	overridden := policy.For("premium", models.WalletLimits{DailyWithdrawal: amount(50), MinBalance: amount(0)})
+	if *overridden.MaxOperation != models.NewAmount(10000, 0) || *overridden.DailyWithdrawal != models.NewAmount(50, 0) ||
+		overridden.MinBalance == nil || *overridden.MinBalance != 0 {
+		t.Errorf("Unexpected overridden limits: %+v", overridden)
+	}
+
+	if !policy.HasTier(models.DefaultWalletTier) || !policy.HasTier("premium") || policy.HasTier("gold") {
+		t.Error("Unexpected tiers")
+	}
+}
+
+func TestPolicy_Nil(t *testing.T) {
+	var policy *Policy
+	limits := policy.For(models.DefaultWalletTier, models.WalletLimits{MaxBalance: amount(100)})
+	if limits.MaxOperation != nil || *limits.MaxBalance != models.NewAmount(100, 0) {
+		t.Errorf("Unexpected limits: %+v", limits)
+	}
+}
+
+func TestNewPolicy_Invalid(t *testing.T) {
+	configs := []Config{
+		{Default: models.WalletLimits{MaxOperation: amount(-1)}},
+		{Default: models.WalletLimits{MinBalance: amount(10), MaxBalance: amount(5)}},
+		{Default: models.WalletLimits{MaxBalance: amount(5)}, Tiers: map[string]models.WalletLimits{"vip": {MinBalance: amount(10)}}},
+		{Tiers: map[string]models.WalletLimits{"": {}}},
+	}
+	for i, cfg := range configs {
+		if _, err := NewPolicy(cfg); err == nil {
+			t.Errorf("Config %d: expected an error", i)
+		}
+	}
+}
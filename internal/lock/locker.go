@@ -0,0 +1,57 @@
+// Package lock serialises balance changes per wallet. The repository locks
+// wallet rows while it changes them; a Locker additionally orders the
+// repository calls of service operations on the same wallets. Reads a service
+// does before it locks, such as loading the wallet or pricing a fee or a
+// conversion, are not covered. The memory locker holds its locks from Lock
+// until unlock, the Postgres one only for the database transactions of the
+// operation.
+package lock
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	BackendMemory   = "memory"
+	BackendPostgres = "postgres"
+)
+
+// Locker acquires exclusive locks on wallets. Lock blocks until every wallet
+// is locked and returns the context the operation must run with and a
+// function that releases the locks. A locker may instead attach a TxHook to
+// the context and lock in the transactions of the operation.
+type Locker interface {
+	Lock(ctx context.Context, walletIDs ...uuid.UUID) (opCtx context.Context, unlock func(), err error)
+}
+
+// TxHook runs in each database transaction of an operation right after it
+// begins, before any of its statements.
+type TxHook func(ctx context.Context, tx *sqlx.Tx) error
+
+type txHookKey struct{}
+
+func withTxHook(ctx context.Context, hook TxHook) context.Context {
+	return context.WithValue(ctx, txHookKey{}, hook)
+}
+
+// TxHookFrom returns the hook a locker attached to ctx, or nil.
+func TxHookFrom(ctx context.Context) TxHook {
+	hook, _ := ctx.Value(txHookKey{}).(TxHook)
+	return hook
+}
+
+// orderedIDs returns the distinct IDs in the order their locks must be taken,
+// the same order the repository uses for row locks, so that two operations on
+// overlapping wallets cannot deadlock.
+func orderedIDs(ids []uuid.UUID) []uuid.UUID {
+	ordered := slices.Clone(ids)
+	slices.SortFunc(ordered, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return slices.Compact(ordered)
+}
@@ -0,0 +1,89 @@
+package lock
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryLocker serialises operations within a single process. Entries are
+// reference counted and removed as soon as nobody holds or waits for them,
+// so memory stays proportional to the number of wallets in use.
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[uuid.UUID]*memoryLock
+}
+
+// memoryLock is a mutex built on a channel so that waiting for it can be
+// abandoned when the context is done.
+type memoryLock struct {
+	held chan struct{}
+	refs int
+}
+
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{locks: make(map[uuid.UUID]*memoryLock)}
+}
+
+func (l *MemoryLocker) Lock(ctx context.Context, walletIDs ...uuid.UUID) (context.Context, func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	ids := orderedIDs(walletIDs)
+	entries := make([]*memoryLock, 0, len(ids))
+	unlock := func() {
+		for i := len(entries) - 1; i >= 0; i-- {
+			<-entries[i].held
+			l.release(ids[i])
+		}
+	}
+
+	for _, id := range ids {
+		entry := l.acquire(id)
+		select {
+		case entry.held <- struct{}{}:
+			entries = append(entries, entry)
+		case <-ctx.Done():
+			l.release(id)
+			unlock()
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	return ctx, unlock, nil
+}
+
+// acquire returns the lock for id, registering the caller as a user of it.
+func (l *MemoryLocker) acquire(id uuid.UUID) *memoryLock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, exists := l.locks[id]
+	if !exists {
+		entry = &memoryLock{held: make(chan struct{}, 1)}
+		l.locks[id] = entry
+	}
+	entry.refs++
+	return entry
+}
+
+// release unregisters the caller and evicts the lock once it is unused.
+func (l *MemoryLocker) release(id uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := l.locks[id]
+	entry.refs--
+	if entry.refs == 0 {
+		delete(l.locks, id)
+	}
+}
+
+// size returns the number of wallets currently locked or waited for.
+func (l *MemoryLocker) size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.locks)
+}
@@ -0,0 +1,101 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMemoryLocker_SerialisesSameWallet(t *testing.T) {
+	locker := NewMemoryLocker()
+	walletID := uuid.New()
+
+	_, unlock, err := locker.Lock(context.Background(), walletID)
+	if err != nil {
+		t.Fatalf("Failed to lock: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		_, unlockSecond, _ := locker.Lock(context.Background(), walletID)
+		close(acquired)
+		unlockSecond()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected second lock to wait for the first")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unlock()
+	<-acquired
+}
+
+func TestMemoryLocker_EvictsReleasedWallets(t *testing.T) {
+	locker := NewMemoryLocker()
+	first, second := uuid.New(), uuid.New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Opposite orders must not deadlock
+			ids := []uuid.UUID{first, second}
+			if i%2 == 0 {
+				ids = []uuid.UUID{second, first}
+			}
+			_, unlock, _ := locker.Lock(context.Background(), ids...)
+			unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	if size := locker.size(); size != 0 {
+		t.Errorf("Expected all locks to be evicted, got %d", size)
+	}
+}
+
+func TestMemoryLocker_DuplicateIDs(t *testing.T) {
+	locker := NewMemoryLocker()
+	walletID := uuid.New()
+
+	_, unlock, err := locker.Lock(context.Background(), walletID, walletID)
+	if err != nil {
+		t.Fatalf("Failed to lock: %v", err)
+	}
+	unlock()
+
+	if size := locker.size(); size != 0 {
+		t.Errorf("Expected lock to be evicted, got %d", size)
+	}
+}
+
+func TestMemoryLocker_CancelledWait(t *testing.T) {
+	locker := NewMemoryLocker()
+	first, second := uuid.New(), uuid.New()
+
+	_, unlock, err := locker.Lock(context.Background(), second)
+	if err != nil {
+		t.Fatalf("Failed to lock: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Locks taken before the wait timed out must be released again
+	if _, _, err := locker.Lock(ctx, first, second); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+
+	unlock()
+
+	if size := locker.size(); size != 0 {
+		t.Errorf("Expected all locks to be evicted, got %d", size)
+	}
+}
@@ -0,0 +1,47 @@
+package lock
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresLocker serialises operations across all instances sharing the
+// database. Locks are transaction-level advisory locks taken at the start of
+// the operation's own transaction, so an operation holds a single pooled
+// connection; they are released when that transaction ends, and if the
+// process dies the connection closes and Postgres releases them by itself.
+type PostgresLocker struct{}
+
+func NewPostgresLocker() *PostgresLocker {
+	return &PostgresLocker{}
+}
+
+func (l *PostgresLocker) Lock(ctx context.Context, walletIDs ...uuid.UUID) (context.Context, func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	ids := orderedIDs(walletIDs)
+	hook := func(ctx context.Context, tx *sqlx.Tx) error {
+		for _, id := range ids {
+			if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, advisoryKey(id)); err != nil {
+				return fmt.Errorf("failed to lock wallet %s: %w", id, err)
+			}
+		}
+		return nil
+	}
+	return withTxHook(ctx, hook), func() {}, nil
+}
+
+// advisoryKey maps a wallet ID onto the 64-bit advisory lock key space. A
+// collision only makes two wallets share a lock, it never breaks exclusion.
+func advisoryKey(id uuid.UUID) int64 {
+	h := fnv.New64a()
+	h.Write(id[:])
+	return int64(binary.BigEndian.Uint64(h.Sum(nil)))
+}
@@ -0,0 +1,56 @@
+package lock
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestPostgresLocker_LocksInOrderWithinTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	locker := NewPostgresLocker()
+	a := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	b := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+	// Nothing is locked until the operation's transaction begins
+	ctx, unlock, err := locker.Lock(context.Background(), b, a)
+	if err != nil {
+		t.Fatalf("Failed to lock: %v", err)
+	}
+	defer unlock()
+
+	hook := TxHookFrom(ctx)
+	if hook == nil {
+		t.Fatal("Expected a transaction hook")
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock\\(\\$1\\)").
+		WithArgs(advisoryKey(a)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SELECT pg_advisory_xact_lock\\(\\$1\\)").
+		WithArgs(advisoryKey(b)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	tx, err := sqlx.NewDb(db, "sqlmock").BeginTxx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to begin: %v", err)
+	}
+	if err := hook(ctx, tx); err != nil {
+		t.Fatalf("Failed to run hook: %v", err)
+	}
+	tx.Rollback()
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
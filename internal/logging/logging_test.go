@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew_AddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "debug")
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger.With(slog.String("component", "test")).InfoContext(ctx, "hello")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+
+	if record["request_id"] != "req-1" || record["component"] != "test" {
+		t.Errorf("Unexpected record %v", record)
+	}
+}
+
+func TestNew_AddsTraceID(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info")
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	logger.InfoContext(ctx, "hello")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+	if record["trace_id"] != traceID.String() {
+		t.Errorf("Expected trace_id %s, got %v", traceID, record)
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "WARN")
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	logger.Info("dropped")
+	if buf.Len() != 0 {
+		t.Errorf("Expected info record to be dropped, got %s", buf.String())
+	}
+
+	if _, err := New(&buf, "verbose"); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
@@ -0,0 +1,103 @@
+// Package metrics defines the Prometheus metrics of the wallet service. They
+// are registered with the default registry and served on /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"wallet_service/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wallet"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	Operations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operations_total",
+		Help:      "Ledger entries written, by operation type and currency.",
+	}, []string{"operation_type", "currency"})
+
+	OperationAmount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "operation_amount_total",
+		Help:      "Sum of ledger entry amounts in major currency units, by operation type and currency.",
+	}, []string{"operation_type", "currency"})
+
+	InsufficientFunds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "insufficient_funds_total",
+		Help:      "Operations rejected because the available balance was too low.",
+	}, []string{"operation"})
+
+	LimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "limit_rejections_total",
+		Help:      "Operations rejected because they would exceed a wallet limit.",
+	}, []string{"operation"})
+
+	Transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Transfer attempts by outcome: ok or the error code.",
+	}, []string{"outcome"})
+
+	LockWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "lock_wait_seconds",
+		Help:      "Time spent waiting for wallet locks.",
+		Buckets:   []float64{.0005, .001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	})
+
+	DBTransactionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_transaction_duration_seconds",
+		Help:      "Time database transactions stay open, by operation and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "outcome"})
+)
+
+// Handler serves the registered metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDBStats exports the connection pool statistics of db.
+func RegisterDBStats(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "wallet"))
+}
+
+// ObserveTransactions counts the ledger entries and their amounts.
+func ObserveTransactions(transactions ...models.Transaction) {
+	for _, t := range transactions {
+		labels := []string{string(t.OperationType), string(t.Currency)}
+		Operations.WithLabelValues(labels...).Inc()
+		OperationAmount.WithLabelValues(labels...).Add(majorUnits(t.Amount))
+	}
+}
+
+func majorUnits(amount models.Amount) float64 {
+	scale := 1.0
+	for i := 0; i < models.AmountScale; i++ {
+		scale *= 10
+	}
+	return float64(amount.MinorUnits()) / scale
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"testing"
+
+	"wallet_service/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestObserveTransactions(t *testing.T) {
+	before := testutil.ToFloat64(OperationAmount.WithLabelValues(string(models.DEPOSIT), "EUR"))
+
+	ObserveTransactions(
+		models.Transaction{OperationType: models.DEPOSIT, Amount: models.NewAmount(10, 25), Currency: "EUR"},
+		models.Transaction{OperationType: models.DEPOSIT, Amount: models.NewAmount(0, 75), Currency: "EUR"},
+	)
+
+	if got := testutil.ToFloat64(OperationAmount.WithLabelValues(string(models.DEPOSIT), "EUR")) - before; got != 11 {
+		t.Errorf("Expected amount to grow by 11, got %v", got)
+	}
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"wallet_service/internal/apperrors"
+)
+
+// AmountScale is the number of fractional digits an Amount carries. It matches
+// the DECIMAL(15,2) columns money is stored in.
+const AmountScale = 2
+
+const amountFactor = 100 // 10^AmountScale
+
+var (
+	ErrInvalidAmountFormat = apperrors.New(apperrors.CodeInvalidAmount, "invalid amount format")
+	ErrAmountPrecision     = apperrors.New(apperrors.CodeInvalidAmount, "amount has too many fractional digits")
+	ErrAmountOverflow      = apperrors.New(apperrors.CodeInvalidAmount, "amount is out of range")
+)
+
+// Amount is an exact monetary value expressed in minor units (cents).
+// It is serialized as a decimal number both in JSON and in the database, so
+// money never passes through binary floating point.
+type Amount int64
+
+// NewAmount builds an Amount from whole and minor units, e.g. NewAmount(10, 50)
+// is 10.50.
+func NewAmount(major, minor int64) Amount {
+	return Amount(major*amountFactor + minor)
+}
+
+// ParseAmount parses a plain decimal string such as "10", "-3.5" or "0.01".
+// Exponents, thousands separators and more than AmountScale fractional digits
+// are rejected.
+func ParseAmount(s string) (Amount, error) {
+	if s == "" {
+		return 0, ErrInvalidAmountFormat
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" || !isDigits(intPart) || (hasDot && (fracPart == "" || !isDigits(fracPart))) {
+		return 0, ErrInvalidAmountFormat
+	}
+	if len(fracPart) > AmountScale {
+		return 0, ErrAmountPrecision
+	}
+
+	major, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil || major > math.MaxInt64/amountFactor {
+		return 0, ErrAmountOverflow
+	}
+
+	var minor int64
+	if fracPart != "" {
+		fracPart += strings.Repeat("0", AmountScale-len(fracPart))
+		minor, _ = strconv.ParseInt(fracPart, 10, 64)
+	}
+
+	value := major*amountFactor + minor
+	if value < 0 {
+		return 0, ErrAmountOverflow
+	}
+	if negative {
+		value = -value
+	}
+	return Amount(value), nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// MinorUnits returns the amount in cents.
+func (a Amount) MinorUnits() int64 {
+	return int64(a)
+}
+
+// String formats the amount with exactly AmountScale fractional digits.
+func (a Amount) String() string {
+	v := int64(a)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+	// Work with uint64 so that math.MinInt64 does not overflow on negation.
+	u := uint64(v)
+	if v < 0 {
+		u = uint64(-(v + 1)) + 1
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, u/amountFactor, AmountScale, u%amountFactor)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts either a JSON number or a string holding a decimal,
+// so clients that cannot emit exact decimal numbers can quote them.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	parsed, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+func (a *Amount) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		*a = NewAmount(v, 0)
+		return nil
+	case nil:
+		*a = 0
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Amount", src)
+	}
+
+	parsed, err := ParseAmount(s)
+	if err != nil {
+		return fmt.Errorf("cannot scan %q into Amount: %w", s, err)
+	}
+	*a = parsed
+	return nil
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Amount
+	}{
+		{"0", 0},
+		{"10", 1000},
+		{"10.5", 1050},
+		{"10.05", 1005},
+		{"0.01", 1},
+		{"-3.10", -310},
+		{"+7.00", 700},
+	}
+
+	for _, tt := range tests {
+		amount, err := ParseAmount(tt.input)
+		if err != nil {
+			t.Errorf("ParseAmount(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if amount != tt.expected {
+			t.Errorf("ParseAmount(%q) = %d, expected %d", tt.input, amount, tt.expected)
+		}
+	}
+}
+
+func TestParseAmount_Invalid(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected error
+	}{
+		{"", ErrInvalidAmountFormat},
+		{"abc", ErrInvalidAmountFormat},
+		{"1e3", ErrInvalidAmountFormat},
+		{"1.", ErrInvalidAmountFormat},
+		{".5", ErrInvalidAmountFormat},
+		{"1,000", ErrInvalidAmountFormat},
+		{"0.001", ErrAmountPrecision},
+		{"10.999", ErrAmountPrecision},
+		{"99999999999999999999", ErrAmountOverflow},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseAmount(tt.input)
+		if !errors.Is(err, tt.expected) {
+			t.Errorf("ParseAmount(%q) error = %v, expected %v", tt.input, err, tt.expected)
+		}
+	}
+}
+
+func TestAmount_String(t *testing.T) {
+	tests := []struct {
+		amount   Amount
+		expected string
+	}{
+		{0, "0.00"},
+		{1, "0.01"},
+		{1050, "10.50"},
+		{-5, "-0.05"},
+		{-12345, "-123.45"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.expected {
+			t.Errorf("Amount(%d).String() = %q, expected %q", tt.amount, got, tt.expected)
+		}
+	}
+}
+
+func TestAmount_JSON(t *testing.T) {
+	var op WalletOperation
+	if err := json.Unmarshal([]byte(`{"amount": 0.1}`), &op); err != nil {
+		t.Fatalf("Failed to unmarshal amount: %v", err)
+	}
+	if op.Amount != 10 {
+		t.Errorf("Expected 10 minor units, got %d", op.Amount)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount": "19.99"}`), &op); err != nil {
+		t.Fatalf("Failed to unmarshal quoted amount: %v", err)
+	}
+	if op.Amount != 1999 {
+		t.Errorf("Expected 1999 minor units, got %d", op.Amount)
+	}
+
+	err := json.Unmarshal([]byte(`{"amount": 1.005}`), &op)
+	if !errors.Is(err, ErrAmountPrecision) {
+		t.Errorf("Expected precision error, got %v", err)
+	}
+
+	data, err := json.Marshal(Wallet{Balance: 1999})
+	if err != nil {
+		t.Fatalf("Failed to marshal wallet: %v", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("Failed to decode wallet JSON: %v", err)
+	}
+	if string(raw["balance"]) != "19.99" {
+		t.Errorf("Expected balance 19.99, got %s", raw["balance"])
+	}
+}
+
+func TestAmount_Scan(t *testing.T) {
+	var a Amount
+	if err := a.Scan([]byte("150.25")); err != nil {
+		t.Fatalf("Failed to scan amount: %v", err)
+	}
+	if a != 15025 {
+		t.Errorf("Expected 15025 minor units, got %d", a)
+	}
+
+	if err := a.Scan(1.5); err == nil {
+		t.Error("Expected error when scanning float64, got nil")
+	}
+
+	value, err := a.Value()
+	if err != nil {
+		t.Fatalf("Failed to get driver value: %v", err)
+	}
+	if value != "150.25" {
+		t.Errorf("Expected driver value 150.25, got %v", value)
+	}
+}
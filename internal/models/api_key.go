@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// APIKey authenticates a client. Only the hash of the key is stored; Prefix
+// keeps its first characters so that keys can be told apart.
+type APIKey struct {
+	ID        uuid.UUID      `json:"id" db:"id"`
+	Name      string         `json:"name" db:"name"`
+	Prefix    string         `json:"prefix" db:"prefix"`
+	OwnerID   *string        `json:"owner_id,omitempty" db:"owner_id"`
+	Scopes    pq.StringArray `json:"scopes" db:"scopes"`
+	Roles     pq.StringArray `json:"roles" db:"roles"`
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time     `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// CreateAPIKeyRequest is the body of POST /api/v1/admin/api-keys.
+// A key acts for OwnerID, or as a service account without one; service
+// accounts need the admin role to act on wallets.
+type CreateAPIKeyRequest struct {
+	Name    string   `json:"name"`
+	OwnerID *string  `json:"ownerId,omitempty"`
+	Scopes  []string `json:"scopes"`
+	Roles   []string `json:"roles"`
+}
+
+// CreatedAPIKey is returned when a key is created. It is the only time the
+// key itself is shown.
+type CreatedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"wallet_service/internal/apperrors"
+
+	"github.com/google/uuid"
+)
+
+// BatchMode tells how a batch fails: atomic batches are booked in a single
+// database transaction and fail as a whole, best-effort batches execute
+// every item on its own.
+type BatchMode string
+
+const (
+	BatchAtomic     BatchMode = "atomic"
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+const MaxBatchSize = 1000
+
+// TRANSFER is the batch item type of transfers; the ledger records them as
+// TRANSFER_OUT and TRANSFER_IN.
+const TRANSFER OperationType = "TRANSFER"
+
+// BatchItem is one instruction of a batch. Deposits and withdrawals use
+// WalletID, transfers FromWalletID and ToWalletID and are converted at the
+// current rate when the wallets hold different currencies.
+type BatchItem struct {
+	OperationType OperationType `json:"operationType"`
+	WalletID      uuid.UUID     `json:"walletId,omitempty"`
+	FromWalletID  uuid.UUID     `json:"fromWalletId,omitempty"`
+	ToWalletID    uuid.UUID     `json:"toWalletId,omitempty"`
+	Amount        Amount        `json:"amount"`
+	Currency      Currency      `json:"currency"`
+}
+
+// BatchRequest is the body of POST /api/v1/wallet/batch. The mode defaults
+// to atomic.
+type BatchRequest struct {
+	Mode  BatchMode   `json:"mode"`
+	Items []BatchItem `json:"items"`
+}
+
+// Outcomes of batch items. Items of a failed atomic batch other than the
+// failing one are rolled back, or never reached.
+const (
+	BatchItemSucceeded  = "succeeded"
+	BatchItemFailed     = "failed"
+	BatchItemRolledBack = "rolled_back"
+)
+
+type BatchError struct {
+	Code    apperrors.Code `json:"code"`
+	Message string         `json:"message"`
+}
+
+// BatchItemResult is the outcome of the item at Index: the entry of a
+// deposit or withdrawal, or the transfer, or why it failed.
+type BatchItemResult struct {
+	Index       int          `json:"index"`
+	Status      string       `json:"status"`
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Transfer    *Transfer    `json:"transfer,omitempty"`
+	Fee         *Fee         `json:"fee,omitempty"`
+	Error       *BatchError  `json:"error,omitempty"`
+}
+
+type BatchResult struct {
+	Mode      BatchMode         `json:"mode"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
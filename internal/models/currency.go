@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"wallet_service/internal/apperrors"
+)
+
+// Currency is an ISO 4217 alphabetic currency code.
+type Currency string
+
+// currencyExponents lists the supported currencies with their ISO 4217 minor
+// unit exponent. Amounts are stored with AmountScale fractional digits, so only
+// currencies with an exponent of at most AmountScale can be supported.
+var currencyExponents = map[Currency]int{
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CHF": 2,
+	"RUB": 2,
+	"KZT": 2,
+	"UAH": 2,
+	"BYN": 2,
+	"GEL": 2,
+	"AMD": 2,
+	"AZN": 2,
+	"TRY": 2,
+	"CNY": 2,
+	"INR": 2,
+	"AED": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"UZS": 2,
+	"KGS": 2,
+}
+
+var (
+	ErrCurrencyRequired    = apperrors.New(apperrors.CodeInvalidCurrency, "currency is required")
+	ErrUnsupportedCurrency = apperrors.New(apperrors.CodeInvalidCurrency, "unsupported currency")
+)
+
+// ParseCurrency normalizes s to upper case and checks that it is supported.
+func ParseCurrency(s string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(s)))
+	if c == "" {
+		return "", ErrCurrencyRequired
+	}
+	if _, ok := currencyExponents[c]; !ok {
+		return "", ErrUnsupportedCurrency
+	}
+	return c, nil
+}
+
+// SupportedCurrencies returns the supported currency codes in sorted order.
+func SupportedCurrencies() []Currency {
+	currencies := make([]Currency, 0, len(currencyExponents))
+	for c := range currencyExponents {
+		currencies = append(currencies, c)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i] < currencies[j] })
+	return currencies
+}
+
+// Exponent returns the number of minor unit digits of the currency.
+func (c Currency) Exponent() int {
+	return currencyExponents[c]
+}
+
+// ValidateAmount rejects amounts with more fractional digits than the
+// currency allows, e.g. 10.50 JPY.
+func (c Currency) ValidateAmount(a Amount) error {
+	step := int64(1)
+	for i := c.Exponent(); i < AmountScale; i++ {
+		step *= 10
+	}
+	if int64(a)%step != 0 {
+		return apperrors.New(apperrors.CodeInvalidAmount,
+			fmt.Sprintf("%s amounts allow at most %d fractional digits", c, c.Exponent()))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCurrency(t *testing.T) {
+	currency, err := ParseCurrency(" eur ")
+	if err != nil {
+		t.Fatalf("Failed to parse currency: %v", err)
+	}
+	if currency != "EUR" {
+		t.Errorf("Expected EUR, got %v", currency)
+	}
+
+	if _, err := ParseCurrency("ABC"); !errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("Expected unsupported currency error, got %v", err)
+	}
+
+	if _, err := ParseCurrency(""); !errors.Is(err, ErrCurrencyRequired) {
+		t.Errorf("Expected currency required error, got %v", err)
+	}
+}
+
+func TestCurrency_ValidateAmount(t *testing.T) {
+	tests := []struct {
+		currency Currency
+		amount   Amount
+		valid    bool
+	}{
+		{"USD", NewAmount(10, 55), true},
+		{"JPY", NewAmount(1000, 0), true},
+		{"JPY", NewAmount(1000, 50), false},
+		{"JPY", NewAmount(0, 1), false},
+	}
+
+	for _, tt := range tests {
+		err := tt.currency.ValidateAmount(tt.amount)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s.ValidateAmount(%v) error = %v, expected valid=%v", tt.currency, tt.amount, err, tt.valid)
+		}
+	}
+}
+
+func TestSupportedCurrencies_WithinAmountScale(t *testing.T) {
+	for _, currency := range SupportedCurrencies() {
+		if currency.Exponent() > AmountScale {
+			t.Errorf("Currency %s needs %d fractional digits, amounts only carry %d", currency, currency.Exponent(), AmountScale)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"wallet_service/internal/apperrors"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidCursor = apperrors.New(apperrors.CodeInvalidCursor, "invalid cursor")
+
+// Cursor points at the last row of a listing page sorted by a field of type
+// F. Value holds the sort column of that row and ID breaks ties, so the next
+// page can continue right after it.
+type Cursor[F ~string] struct {
+	SortBy F         `json:"s"`
+	Order  SortOrder `json:"o"`
+	Value  string    `json:"v"`
+	ID     uuid.UUID `json:"id"`
+}
+
+// Encode returns the opaque form of c handed out to clients.
+func (c Cursor[F]) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by Encode.
+func DecodeCursor[F ~string](s string) (*Cursor[F], error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor[F]
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := WalletCursor{SortBy: WalletSortByBalance, Order: SortAsc, Value: "10.00", ID: uuid.New()}
+
+	decoded, err := DecodeCursor[WalletSortField](cursor.Encode())
+	if err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+	if *decoded != cursor {
+		t.Errorf("Expected %+v, got %+v", cursor, *decoded)
+	}
+
+	for _, s := range []string{"not base64!", "bm90IGpzb24", TransactionCursor{SortBy: SortByAmount}.Encode()} {
+		if _, err := DecodeCursor[TransactionSortField](s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("Expected invalid cursor error for %q, got %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+import "github.com/google/uuid"
+
+// Fee is charged to the wallet an operation debits, on top of the operation
+// amount, and credited to the revenue wallet of its currency. Transaction is
+// the FEE entry of the paying wallet, set once the fee is booked.
+type Fee struct {
+	Amount          Amount       `json:"amount"`
+	Currency        Currency     `json:"currency"`
+	RevenueWalletID uuid.UUID    `json:"revenue_wallet_id"`
+	Transaction     *Transaction `json:"transaction,omitempty"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "ACTIVE"
+	HoldCaptured HoldStatus = "CAPTURED"
+	HoldVoided   HoldStatus = "VOIDED"
+	HoldExpired  HoldStatus = "EXPIRED"
+)
+
+// Hold reserves part of a wallet balance. While active it reduces the
+// available balance but not the ledger balance; capturing it debits the wallet,
+// voiding or expiring it releases the reservation.
+type Hold struct {
+	ID                   uuid.UUID  `json:"id" db:"id"`
+	WalletID             uuid.UUID  `json:"wallet_id" db:"wallet_id"`
+	Amount               Amount     `json:"amount" db:"amount"`
+	Currency             Currency   `json:"currency" db:"currency"`
+	CapturedAmount       Amount     `json:"captured_amount" db:"captured_amount"`
+	Status               HoldStatus `json:"status" db:"status"`
+	CaptureTransactionID *uuid.UUID `json:"capture_transaction_id,omitempty" db:"capture_transaction_id"`
+	ExpiresAt            time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// HoldRequest is the body of POST /api/v1/wallets/:wallet_uuid/holds.
+// ExpiresInSeconds is optional and defaults to the configured hold TTL.
+type HoldRequest struct {
+	Amount           Amount   `json:"amount"`
+	Currency         Currency `json:"currency"`
+	ExpiresInSeconds int      `json:"expiresInSeconds"`
+}
+
+// CaptureRequest is the optional body of POST /api/v1/holds/:hold_id/capture.
+// A zero amount captures the whole hold; any uncaptured rest is released.
+type CaptureRequest struct {
+	Amount Amount `json:"amount"`
+}
+
+type HoldCapture struct {
+	Hold        *Hold        `json:"hold"`
+	Transaction *Transaction `json:"transaction"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// IdempotencyRecord stores the outcome of a request made with an
+// Idempotency-Key header. ResponseStatus is nil while the original request is
+// still being processed.
+type IdempotencyRecord struct {
+	Scope          string     `db:"scope"`
+	Key            string     `db:"key"`
+	RequestHash    string     `db:"request_hash"`
+	ResponseStatus *int       `db:"response_status"`
+	ResponseBody   []byte     `db:"response_body"`
+	CreatedAt      time.Time  `db:"created_at"`
+	CompletedAt    *time.Time `db:"completed_at"`
+}
+
+func (r *IdempotencyRecord) Completed() bool {
+	return r.ResponseStatus != nil
+}
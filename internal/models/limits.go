@@ -0,0 +1,97 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"wallet_service/internal/apperrors"
+
+	"github.com/google/uuid"
+)
+
+// DefaultWalletTier is the tier of wallets that were not assigned one. It
+// always exists, with the global limits unless the configuration defines it.
+const DefaultWalletTier = "standard"
+
+var ErrInvalidLimits = apperrors.New(apperrors.CodeInvalidRequest, "limits must not be negative and min_balance must not exceed max_balance")
+
+// WalletLimits caps what a wallet may do, in units of the wallet currency.
+// A nil field is not limited. Daily and monthly totals are sums of the
+// operation amounts, without fees, since midnight UTC and the first day of
+// the month UTC.
+type WalletLimits struct {
+	MaxOperation      *Amount `json:"max_operation,omitempty"`
+	DailyWithdrawal   *Amount `json:"daily_withdrawal,omitempty"`
+	MonthlyWithdrawal *Amount `json:"monthly_withdrawal,omitempty"`
+	DailyTransfer     *Amount `json:"daily_transfer,omitempty"`
+	MonthlyTransfer   *Amount `json:"monthly_transfer,omitempty"`
+	MaxBalance        *Amount `json:"max_balance,omitempty"`
+	MinBalance        *Amount `json:"min_balance,omitempty"`
+}
+
+// Override returns l with every limit set in o replaced.
+func (l WalletLimits) Override(o WalletLimits) WalletLimits {
+	for _, f := range []struct{ dst, src **Amount }{
+		{&l.MaxOperation, &o.MaxOperation},
+		{&l.DailyWithdrawal, &o.DailyWithdrawal},
+		{&l.MonthlyWithdrawal, &o.MonthlyWithdrawal},
+		{&l.DailyTransfer, &o.DailyTransfer},
+		{&l.MonthlyTransfer, &o.MonthlyTransfer},
+		{&l.MaxBalance, &o.MaxBalance},
+		{&l.MinBalance, &o.MinBalance},
+	} {
+		if *f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	return l
+}
+
+func (l WalletLimits) Validate() error {
+	for _, limit := range []*Amount{l.MaxOperation, l.DailyWithdrawal, l.MonthlyWithdrawal, l.DailyTransfer, l.MonthlyTransfer, l.MaxBalance, l.MinBalance} {
+		if limit != nil && *limit < 0 {
+			return ErrInvalidLimits
+		}
+	}
+	if l.MinBalance != nil && l.MaxBalance != nil && *l.MinBalance > *l.MaxBalance {
+		return ErrInvalidLimits
+	}
+	return nil
+}
+
+// Value stores the limits as a JSON object.
+func (l WalletLimits) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+func (l *WalletLimits) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = WalletLimits{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into WalletLimits", src)
+	}
+}
+
+// WalletLimitsRequest is the body of PUT /api/v1/wallets/:wallet_uuid/limits.
+// An empty tier keeps the current one; Overrides replace the previous
+// overrides as a whole.
+type WalletLimitsRequest struct {
+	Tier      string       `json:"tier"`
+	Overrides WalletLimits `json:"overrides"`
+}
+
+// WalletLimitsView shows the tier and overrides of a wallet and the limits
+// that result from them.
+type WalletLimitsView struct {
+	WalletID  uuid.UUID    `json:"wallet_id"`
+	Tier      string       `json:"tier"`
+	Overrides WalletLimits `json:"overrides"`
+	Effective WalletLimits `json:"effective"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CreditLimitRequest is the body of PUT /api/v1/wallets/:wallet_uuid/overdraft.
+// A zero credit limit turns overdraft off.
+type CreditLimitRequest struct {
+	CreditLimit Amount `json:"credit_limit"`
+}
+
+// OverdraftPeriod is a stretch of time a wallet spent with a negative
+// balance. EndedAt is nil while the wallet is still overdrawn.
+type OverdraftPeriod struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	WalletID      uuid.UUID  `json:"wallet_id" db:"wallet_id"`
+	StartedAt     time.Time  `json:"started_at" db:"started_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+	LowestBalance Amount     `json:"lowest_balance" db:"lowest_balance"`
+}
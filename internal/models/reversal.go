@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReversalRequest is the optional body of
+// POST /api/v1/transactions/:transaction_id/reversals. A zero amount reverses
+// whatever is left of the transaction.
+type ReversalRequest struct {
+	Amount Amount `json:"amount"`
+}
+
+// Reversal describes the compensating entries booked for a transaction; a
+// transfer is reversed on both legs. Its ID is the reference ID shared by
+// the entries, each of which points at the entry it reverses.
+type Reversal struct {
+	ID            uuid.UUID `json:"id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Amount        Amount    `json:"amount"`
+	Currency      Currency  `json:"currency"`
+	// RemainingAmount is what can still be reversed afterwards
+	RemainingAmount Amount        `json:"remaining_amount"`
+	Transactions    []Transaction `json:"transactions"`
+	CreatedAt       time.Time     `json:"created_at"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Transaction is an immutable ledger entry describing a single balance change
+// of one wallet. Amount is always positive; the direction of the change is
+// given by BalanceBefore and BalanceAfter. Legs of cross-currency transfers
+// also record the rate, the amount on the other side and the rounding applied.
+// Reversal entries point at the entry they compensate.
+type Transaction struct {
+	ID                    uuid.UUID     `json:"id" db:"id"`
+	WalletID              uuid.UUID     `json:"wallet_id" db:"wallet_id"`
+	OperationType         OperationType `json:"operation_type" db:"operation_type"`
+	Amount                Amount        `json:"amount" db:"amount"`
+	Currency              Currency      `json:"currency" db:"currency"`
+	BalanceBefore         Amount        `json:"balance_before" db:"balance_before"`
+	BalanceAfter          Amount        `json:"balance_after" db:"balance_after"`
+	CounterpartyWalletID  *uuid.UUID    `json:"counterparty_wallet_id,omitempty" db:"counterparty_wallet_id"`
+	ReferenceID           *uuid.UUID    `json:"reference_id,omitempty" db:"reference_id"`
+	ExchangeRate          *string       `json:"exchange_rate,omitempty" db:"exchange_rate"`
+	CounterpartyAmount    *Amount       `json:"counterparty_amount,omitempty" db:"counterparty_amount"`
+	CounterpartyCurrency  *Currency     `json:"counterparty_currency,omitempty" db:"counterparty_currency"`
+	RoundingAdjustment    *string       `json:"rounding_adjustment,omitempty" db:"rounding_adjustment"`
+	ReversedTransactionID *uuid.UUID    `json:"reversed_transaction_id,omitempty" db:"reversed_transaction_id"`
+	CreatedAt             time.Time     `json:"created_at" db:"created_at"`
+}
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// TransactionSortField is a column transactions can be ordered by. Every
+// ordering is made total by using the transaction ID as a tie-breaker.
+type TransactionSortField string
+
+const (
+	SortByCreatedAt TransactionSortField = "created_at"
+	SortByAmount    TransactionSortField = "amount"
+)
+
+const (
+	DefaultTransactionPageSize = 50
+	MaxTransactionPageSize     = 200
+)
+
+type TransactionFilter struct {
+	OperationTypes []OperationType
+	MinAmount      *Amount
+	MaxAmount      *Amount
+	From           *time.Time
+	To             *time.Time
+	SortBy         TransactionSortField
+	Order          SortOrder
+	Limit          int
+	Cursor         *TransactionCursor
+}
+
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
+// TransactionCursor points at the last transaction of a page.
+type TransactionCursor = Cursor[TransactionSortField]
+
+// CursorFor builds the cursor continuing the listing after t.
+func (f TransactionFilter) CursorFor(t Transaction) TransactionCursor {
+	value := t.CreatedAt.UTC().Format(time.RFC3339Nano)
+	if f.SortBy == SortByAmount {
+		value = t.Amount.String()
+	}
+	return TransactionCursor{SortBy: f.SortBy, Order: f.Order, Value: value, ID: t.ID}
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransferRequest is the body of POST /api/v1/transfers. Amount and Currency
+// are debited from the source wallet; an empty currency selects the source
+// wallet's own. For a cross-currency transfer QuoteID
+// may reference a quote to credit exactly the quoted destination amount.
+type TransferRequest struct {
+	FromWalletID uuid.UUID  `json:"fromWalletId"`
+	ToWalletID   uuid.UUID  `json:"toWalletId"`
+	Amount       Amount     `json:"amount"`
+	Currency     Currency   `json:"currency"`
+	QuoteID      *uuid.UUID `json:"quoteId,omitempty"`
+}
+
+// Transfer describes a completed transfer. Its ID is the reference ID shared
+// by both ledger legs.
+type Transfer struct {
+	ID                  uuid.UUID     `json:"id"`
+	FromWalletID        uuid.UUID     `json:"from_wallet_id"`
+	ToWalletID          uuid.UUID     `json:"to_wallet_id"`
+	Amount              Amount        `json:"amount"`
+	Currency            Currency      `json:"currency"`
+	DestinationAmount   Amount        `json:"destination_amount"`
+	DestinationCurrency Currency      `json:"destination_currency"`
+	ExchangeRate        *string       `json:"exchange_rate,omitempty"`
+	QuoteID             *uuid.UUID    `json:"quote_id,omitempty"`
+	Transactions        []Transaction `json:"transactions"`
+	Fee                 *Fee          `json:"fee,omitempty"`
+	CreatedAt           time.Time     `json:"created_at"`
+}
+
+// CurrencyConversion tells how the destination wallet of a cross-currency
+// transfer is credited.
+type CurrencyConversion struct {
+	DestinationAmount   Amount
+	DestinationCurrency Currency
+	Rate                string
+	RoundingAdjustment  string
+	QuoteID             *uuid.UUID
+}
+
+// QuoteRequest is the body of POST /api/v1/transfers/quotes. Like in a
+// TransferRequest, an empty currency selects the source wallet's own.
+type QuoteRequest struct {
+	FromWalletID uuid.UUID `json:"fromWalletId"`
+	ToWalletID   uuid.UUID `json:"toWalletId"`
+	Amount       Amount    `json:"amount"`
+	Currency     Currency  `json:"currency"`
+}
+
+// Quote locks an exchange rate for a transfer between two wallets until
+// ExpiresAt. A quote can be used for a single transfer only.
+type Quote struct {
+	ID                  uuid.UUID  `json:"id" db:"id"`
+	FromWalletID        uuid.UUID  `json:"from_wallet_id" db:"from_wallet_id"`
+	ToWalletID          uuid.UUID  `json:"to_wallet_id" db:"to_wallet_id"`
+	SourceAmount        Amount     `json:"source_amount" db:"source_amount"`
+	SourceCurrency      Currency   `json:"source_currency" db:"source_currency"`
+	DestinationAmount   Amount     `json:"destination_amount" db:"destination_amount"`
+	DestinationCurrency Currency   `json:"destination_currency" db:"destination_currency"`
+	Rate                string     `json:"rate" db:"rate"`
+	RoundingAdjustment  string     `json:"rounding_adjustment" db:"rounding_adjustment"`
+	ExpiresAt           time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt              *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Conversion returns the conversion the quote locked in.
+func (q *Quote) Conversion() *CurrencyConversion {
+	return &CurrencyConversion{
+		DestinationAmount:   q.DestinationAmount,
+		DestinationCurrency: q.DestinationCurrency,
+		Rate:                q.Rate,
+		RoundingAdjustment:  q.RoundingAdjustment,
+		QuoteID:             &q.ID,
+	}
+}
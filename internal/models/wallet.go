@@ -6,11 +6,21 @@ import (
 	"github.com/google/uuid"
 )
 
+// Wallet balances: Balance is the ledger balance, HeldBalance the part of it
+// reserved by active holds and AvailableBalance what is left of it. Wallets
+// with overdraft may spend CreditLimit more, going below zero.
 type Wallet struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	Balance   float64   `json:"balance" db:"balance"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID               uuid.UUID    `json:"id" db:"id"`
+	OwnerID          *string      `json:"owner_id,omitempty" db:"owner_id"`
+	Status           WalletStatus `json:"status" db:"status"`
+	Tier             string       `json:"tier" db:"tier"`
+	Balance          Amount       `json:"balance" db:"balance"`
+	HeldBalance      Amount       `json:"held_balance" db:"held_balance"`
+	AvailableBalance Amount       `json:"available_balance" db:"available_balance"`
+	CreditLimit      Amount       `json:"credit_limit" db:"credit_limit"`
+	Currency         Currency     `json:"currency" db:"currency"`
+	CreatedAt        time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at" db:"updated_at"`
 }
 
 type OperationType string
@@ -18,10 +28,74 @@ type OperationType string
 const (
 	DEPOSIT  OperationType = "DEPOSIT"
 	WITHDRAW OperationType = "WITHDRAW"
+
+	// Ledger-only operation types, they cannot be requested via WalletOperation.
+	TRANSFER_IN  OperationType = "TRANSFER_IN"
+	TRANSFER_OUT OperationType = "TRANSFER_OUT"
+	ADJUSTMENT   OperationType = "ADJUSTMENT"
+	CAPTURE      OperationType = "CAPTURE"
+	REVERSAL     OperationType = "REVERSAL"
+	FEE          OperationType = "FEE"
 )
 
+// WalletOperation is the body of POST /api/v1/wallet. An empty currency
+// selects the wallet's own.
 type WalletOperation struct {
 	WalletID      uuid.UUID     `json:"walletId" db:"wallet_id"`
 	OperationType OperationType `json:"operationType" db:"operation_type"`
-	Amount        float64       `json:"amount" db:"amount"`
+	Amount        Amount        `json:"amount" db:"amount"`
+	Currency      Currency      `json:"currency" db:"currency"`
+}
+
+// CreateWalletRequest is the optional body of POST /api/v1/wallets. An empty
+// currency selects the configured default. OwnerID may only be set by
+// admins; other callers always create wallets they own.
+type CreateWalletRequest struct {
+	Currency Currency `json:"currency"`
+	OwnerID  *string  `json:"ownerId,omitempty"`
+}
+
+// WalletSortField is a column wallets can be ordered by. Like transactions,
+// wallets use their ID as a tie-breaker.
+type WalletSortField string
+
+const (
+	WalletSortByCreatedAt WalletSortField = "created_at"
+	WalletSortByBalance   WalletSortField = "balance"
+)
+
+const (
+	DefaultWalletPageSize = 50
+	MaxWalletPageSize     = 200
+)
+
+type WalletFilter struct {
+	OwnerID    *string
+	Statuses   []WalletStatus
+	Currencies []Currency
+	MinBalance *Amount
+	MaxBalance *Amount
+	From       *time.Time
+	To         *time.Time
+	SortBy     WalletSortField
+	Order      SortOrder
+	Limit      int
+	Cursor     *WalletCursor
+}
+
+type WalletPage struct {
+	Wallets    []Wallet `json:"wallets"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// WalletCursor points at the last wallet of a page.
+type WalletCursor = Cursor[WalletSortField]
+
+// CursorFor builds the cursor continuing the listing after w.
+func (f WalletFilter) CursorFor(w Wallet) WalletCursor {
+	value := w.CreatedAt.UTC().Format(time.RFC3339Nano)
+	if f.SortBy == WalletSortByBalance {
+		value = w.Balance.String()
+	}
+	return WalletCursor{SortBy: f.SortBy, Order: f.Order, Value: value, ID: w.ID}
 }
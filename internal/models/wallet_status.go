@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalletStatus is the lifecycle state of a wallet. Frozen wallets cannot be
+// debited; closed wallets cannot be used at all and never reopen.
+type WalletStatus string
+
+const (
+	WalletActive WalletStatus = "ACTIVE"
+	WalletFrozen WalletStatus = "FROZEN"
+	WalletClosed WalletStatus = "CLOSED"
+)
+
+// CanTransitionTo reports whether a wallet in status s may be moved to status
+// to. Closing additionally requires an empty wallet, and an admin if the
+// wallet is frozen, which is checked when the wallet row is locked.
+func (s WalletStatus) CanTransitionTo(to WalletStatus) bool {
+	switch to {
+	case WalletFrozen:
+		return s == WalletActive
+	case WalletActive:
+		return s == WalletFrozen
+	case WalletClosed:
+		return s == WalletActive || s == WalletFrozen
+	default:
+		return false
+	}
+}
+
+// WalletStatusChange is an entry of the wallet status audit trail.
+type WalletStatusChange struct {
+	ID         uuid.UUID    `json:"id" db:"id"`
+	WalletID   uuid.UUID    `json:"wallet_id" db:"wallet_id"`
+	FromStatus WalletStatus `json:"from_status" db:"from_status"`
+	ToStatus   WalletStatus `json:"to_status" db:"to_status"`
+	Reason     string       `json:"reason" db:"reason"`
+	ChangedBy  string       `json:"changed_by" db:"changed_by"`
+	CreatedAt  time.Time    `json:"created_at" db:"created_at"`
+}
+
+// WalletStatusRequest is the body of the freeze, unfreeze and close
+// endpoints. The reason is mandatory and ends up in the audit trail.
+type WalletStatusRequest struct {
+	Reason string `json:"reason"`
+}
@@ -11,7 +11,7 @@ func TestWalletOperation(t *testing.T) {
 	operation := WalletOperation{
 		WalletID:      walletID,
 		OperationType: DEPOSIT,
-		Amount:        100.0,
+		Amount:        NewAmount(100, 0),
 	}
 
 	if operation.WalletID != walletID {
@@ -22,7 +22,7 @@ func TestWalletOperation(t *testing.T) {
 		t.Errorf("Expected operation type DEPOSIT, got %v", operation.OperationType)
 	}
 
-	if operation.Amount != 100.0 {
+	if operation.Amount != NewAmount(100, 0) {
 		t.Errorf("Expected amount 100.0, got %v", operation.Amount)
 	}
 }
@@ -36,3 +36,21 @@ func TestOperationType(t *testing.T) {
 		t.Errorf("Expected WITHDRAW to be 'WITHDRAW', got %v", WITHDRAW)
 	}
 }
+
+func TestWalletStatus_CanTransitionTo(t *testing.T) {
+	allowed := map[[2]WalletStatus]bool{
+		{WalletActive, WalletFrozen}: true,
+		{WalletFrozen, WalletActive}: true,
+		{WalletActive, WalletClosed}: true,
+		{WalletFrozen, WalletClosed}: true,
+	}
+
+	statuses := []WalletStatus{WalletActive, WalletFrozen, WalletClosed}
+	for _, from := range statuses {
+		for _, to := range statuses {
+			if got := from.CanTransitionTo(to); got != allowed[[2]WalletStatus{from, to}] {
+				t.Errorf("%s -> %s: expected %v, got %v", from, to, !got, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type APIKeyRepositoryInterface interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error
+	// GetActiveAPIKey returns the unrevoked key with the given hash
+	GetActiveAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error)
+}
+
+const apiKeyColumns = `id, name, prefix, owner_id, scopes, roles, created_at, revoked_at`
+
+type APIKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewAPIKeyRepository(db *sqlx.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	query := `INSERT INTO api_keys (id, name, prefix, key_hash, owner_id, scopes, roles)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`
+	err := r.db.GetContext(ctx, &key.CreatedAt, query, key.ID, key.Name, key.Prefix, keyHash, key.OwnerID, key.Scopes, key.Roles)
+	if err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) GetActiveAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`
+	if err := r.db.GetContext(ctx, &key, query, keyHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &keys, query); err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes the key. Revoking an already revoked key keeps the
+// original revocation time.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	query := `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW()) WHERE id = $1 RETURNING ` + apiKeyColumns
+	if err := r.db.GetContext(ctx, &key, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return &key, nil
+}
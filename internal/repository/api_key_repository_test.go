@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet_service/internal/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestAPIKeyRepository_GetActiveAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewAPIKeyRepository(sqlx.NewDb(db, "sqlmock"))
+	keyID := uuid.New()
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE key_hash = \\$1 AND revoked_at IS NULL").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "prefix", "owner_id", "scopes", "roles", "created_at", "revoked_at"}).
+			AddRow(keyID, "billing", "wsk_abcdefgh", nil, "{wallets:read,transfers:write}", "{admin}", time.Now(), nil))
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE key_hash = \\$1 AND revoked_at IS NULL").
+		WithArgs("revoked").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	key, err := repo.GetActiveAPIKey(context.Background(), "hash")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if key.ID != keyID || len(key.Scopes) != 2 || key.Scopes[1] != "transfers:write" || key.Roles[0] != "admin" {
+		t.Errorf("Unexpected key %+v", key)
+	}
+
+	if _, err := repo.GetActiveAPIKey(context.Background(), "revoked"); !errors.Is(err, apperrors.ErrAPIKeyNotFound) {
+		t.Errorf("Expected API key not found, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// BatchOperation is an item of an atomic batch, validated and with its fee
+// and currency conversion priced by the service.
+type BatchOperation struct {
+	Item       models.BatchItem
+	Conversion *models.CurrencyConversion
+	Fee        *models.Fee
+}
+
+// walletIDs returns the wallets the operation books on, the revenue wallet
+// included. Unlike single operations, a batch may credit several revenue
+// wallets, so it locks them up front in order with the others rather than
+// one by one as it goes, which could deadlock with another batch.
+func (op BatchOperation) walletIDs() []uuid.UUID {
+	ids := []uuid.UUID{op.Item.WalletID}
+	if op.Item.OperationType == models.TRANSFER {
+		ids = []uuid.UUID{op.Item.FromWalletID, op.Item.ToWalletID}
+	}
+	if op.Fee != nil {
+		ids = append(ids, op.Fee.RevenueWalletID)
+	}
+	return ids
+}
+
+// BatchItemError tells which item failed an atomic batch.
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+// ExecuteBatch books the operations in order within one database
+// transaction: either all of them are booked or, if one fails, none. Every
+// wallet involved is locked up front in the order of orderedWalletIDs, like
+// a single transfer, so batches cannot deadlock with each other or with
+// single operations. The results carry the entries of each operation.
+func (r *WalletRepository) ExecuteBatch(ctx context.Context, ops []BatchOperation) ([]models.BatchItemResult, error) {
+	ctx, tx, done, err := beginTx(ctx, r.db, "batch")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	var ids []uuid.UUID
+	for _, op := range ops {
+		ids = append(ids, op.walletIDs()...)
+	}
+	wallets, err := lockWallets(ctx, tx, ids...)
+	if err != nil {
+		var missing *missingWalletError
+		if errors.As(err, &missing) {
+			return nil, missingBatchWallet(ops, missing)
+		}
+		return nil, err
+	}
+
+	results := make([]models.BatchItemResult, len(ops))
+	for i, op := range ops {
+		item := op.Item
+		result := models.BatchItemResult{Index: i, Status: models.BatchItemSucceeded}
+		switch item.OperationType {
+		case models.DEPOSIT:
+			result.Transaction, err = r.bookOperation(ctx, tx, wallets, item.WalletID, models.DEPOSIT, item.Amount, item.Amount, item.Currency, nil)
+		case models.WITHDRAW:
+			result.Transaction, err = r.bookOperation(ctx, tx, wallets, item.WalletID, models.WITHDRAW, item.Amount, -item.Amount, item.Currency, op.Fee)
+			result.Fee = op.Fee
+		case models.TRANSFER:
+			result.Transfer, err = r.bookTransfer(ctx, tx, wallets, item.FromWalletID, item.ToWalletID, item.Amount, item.Currency, op.Conversion, op.Fee)
+		default:
+			err = apperrors.ErrInvalidOperationType
+		}
+		if err != nil {
+			return nil, &BatchItemError{Index: i, Err: err}
+		}
+		results[i] = result
+	}
+
+	if err = commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return results, nil
+}
+
+// missingBatchWallet blames the first operation using the missing wallet,
+// with the not found error of the role the wallet plays in it.
+func missingBatchWallet(ops []BatchOperation, missing *missingWalletError) error {
+	for i, op := range ops {
+		item := op.Item
+		switch missing.walletID {
+		case item.WalletID:
+			return &BatchItemError{Index: i, Err: apperrors.ErrWalletNotFound}
+		case item.FromWalletID:
+			return &BatchItemError{Index: i, Err: apperrors.ErrSourceWalletNotFound}
+		case item.ToWalletID:
+			return &BatchItemError{Index: i, Err: apperrors.ErrDestinationWalletNotFound}
+		}
+	}
+	// Only a revenue wallet is left
+	return missing
+}
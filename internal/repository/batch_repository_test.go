@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestWalletRepository_ExecuteBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	fromID, toID := uuid.New(), uuid.New()
+	balances := map[uuid.UUID]string{fromID: "10.00", toID: "1.00"}
+	ops := []BatchOperation{
+		{Item: models.BatchItem{OperationType: models.DEPOSIT, WalletID: fromID, Amount: models.NewAmount(5, 0), Currency: "USD"}},
+		{Item: models.BatchItem{OperationType: models.TRANSFER, FromWalletID: fromID, ToWalletID: toID, Amount: models.NewAmount(3, 0), Currency: "USD"}},
+	}
+
+	// Every wallet is locked once, before anything is booked
+	mock.ExpectBegin()
+	for _, id := range orderedWalletIDs(fromID, toID) {
+		expectLockWallet(mock, id, lockedWallet{Balance: balances[id]})
+	}
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("15.00", fromID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), fromID, models.DEPOSIT, "5.00", "USD", "10.00", "15.00", nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("12.00", fromID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("4.00", toID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), fromID, models.TRANSFER_OUT, "3.00", "USD", "15.00", "12.00", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), toID, models.TRANSFER_IN, "3.00", "USD", "1.00", "4.00", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	results, err := repo.ExecuteBatch(context.Background(), ops)
+	if err != nil {
+		t.Fatalf("Failed to execute batch: %v", err)
+	}
+	if len(results) != 2 || results[0].Transaction == nil || results[1].Transfer == nil {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_ExecuteBatch_RollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID := uuid.New()
+	ops := []BatchOperation{
+		{Item: models.BatchItem{OperationType: models.DEPOSIT, WalletID: walletID, Amount: models.NewAmount(5, 0), Currency: "USD"}},
+		{Item: models.BatchItem{OperationType: models.WITHDRAW, WalletID: walletID, Amount: models.NewAmount(20, 0), Currency: "USD"}},
+	}
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "10.00"})
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("15.00", walletID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), walletID, models.DEPOSIT, "5.00", "USD", "10.00", "15.00", nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectRollback()
+
+	_, err = repo.ExecuteBatch(context.Background(), ops)
+	var itemErr *BatchItemError
+	if !errors.As(err, &itemErr) || itemErr.Index != 1 || !errors.Is(err, apperrors.ErrInsufficientFunds) {
+		t.Fatalf("Expected insufficient funds at item 1, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_ExecuteBatch_MissingWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	fromID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	toID := uuid.MustParse("ffffffff-0000-0000-0000-000000000000")
+	ops := []BatchOperation{
+		{Item: models.BatchItem{OperationType: models.TRANSFER, FromWalletID: fromID, ToWalletID: toID, Amount: models.NewAmount(1, 0), Currency: "USD"}},
+	}
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, fromID, lockedWallet{Balance: "10.00"})
+	mock.ExpectQuery(lockWalletPattern).
+		WithArgs(toID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance"}))
+	mock.ExpectRollback()
+
+	_, err = repo.ExecuteBatch(context.Background(), ops)
+	if !errors.Is(err, apperrors.ErrDestinationWalletNotFound) {
+		t.Fatalf("Expected destination wallet not found, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
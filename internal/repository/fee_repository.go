@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"wallet_service/internal/models"
+	"wallet_service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const creditRevenueQuery = `UPDATE wallets SET balance = balance + $1, updated_at = NOW() WHERE id = $2 RETURNING balance, currency, status`
+
+func feeAmount(fee *models.Fee) models.Amount {
+	if fee == nil {
+		return 0
+	}
+	return fee.Amount
+}
+
+// chargeFee moves the fee from the payer, already locked, to the revenue
+// wallet and records a FEE entry on each referencing the operation charged.
+// The payer's available balance must have been checked by the caller.
+//
+// The revenue wallet is not locked up front: it is credited in place as the
+// last statement of the operation, so fee-bearing operations only queue for
+// its row while committing rather than for their whole transaction. This
+// cannot deadlock, as operations that lock the revenue wallet along with
+// others are serialised with the payer's operations by the service's locker.
+func chargeFee(ctx context.Context, tx *sqlx.Tx, fee *models.Fee, payerID uuid.UUID, wallets map[uuid.UUID]*walletState, referenceID uuid.UUID) error {
+	payer := wallets[payerID]
+	debit := &models.Transaction{
+		ID:                   uuid.New(),
+		WalletID:             payerID,
+		OperationType:        models.FEE,
+		Amount:               fee.Amount,
+		Currency:             fee.Currency,
+		BalanceBefore:        payer.Balance,
+		BalanceAfter:         payer.Balance - fee.Amount,
+		CounterpartyWalletID: &fee.RevenueWalletID,
+		ReferenceID:          &referenceID,
+	}
+	if err := updateBalance(ctx, tx, payerID, debit.BalanceAfter); err != nil {
+		return err
+	}
+	if err := insertTransaction(ctx, tx, debit); err != nil {
+		return err
+	}
+	payer.Balance = debit.BalanceAfter
+
+	revenue, err := creditRevenue(ctx, tx, fee)
+	if err != nil {
+		return err
+	}
+	credit := &models.Transaction{
+		ID:                   uuid.New(),
+		WalletID:             fee.RevenueWalletID,
+		OperationType:        models.FEE,
+		Amount:               fee.Amount,
+		Currency:             fee.Currency,
+		BalanceBefore:        revenue.Balance - fee.Amount,
+		BalanceAfter:         revenue.Balance,
+		CounterpartyWalletID: &payerID,
+		ReferenceID:          &referenceID,
+	}
+	if err := insertTransaction(ctx, tx, credit); err != nil {
+		return err
+	}
+	// The revenue wallet may also be locked by the operation, as a party or
+	// by a batch
+	if wallet, ok := wallets[fee.RevenueWalletID]; ok {
+		wallet.Balance = revenue.Balance
+	}
+
+	fee.Transaction = debit
+	return nil
+}
+
+// creditRevenue adds the fee to the balance of the revenue wallet and returns
+// the wallet's new state.
+func creditRevenue(ctx context.Context, tx *sqlx.Tx, fee *models.Fee) (_ *walletState, err error) {
+	ctx, span := startStatement(ctx, "db.credit_revenue", creditRevenueQuery, tracing.WalletIDKey.String(fee.RevenueWalletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	var revenue walletState
+	if err = tx.GetContext(ctx, &revenue, creditRevenueQuery, fee.Amount, fee.RevenueWalletID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("revenue wallet %s not found", fee.RevenueWalletID)
+		}
+		return nil, fmt.Errorf("failed to credit revenue wallet: %w", err)
+	}
+	// Misconfigurations of the fee schedule are internal errors, not the
+	// client's fault; returning one rolls the credit back
+	if revenue.Currency != fee.Currency {
+		return nil, fmt.Errorf("revenue wallet %s does not hold %s", fee.RevenueWalletID, fee.Currency)
+	}
+	if revenue.Status == models.WalletClosed {
+		return nil, fmt.Errorf("revenue wallet %s is closed", fee.RevenueWalletID)
+	}
+	return &revenue, nil
+}
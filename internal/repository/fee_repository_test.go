@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestWalletRepository_Withdraw_ChargesFee(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID, revenueID := uuid.New(), uuid.New()
+	fee := &models.Fee{Amount: models.NewAmount(0, 50), Currency: "USD", RevenueWalletID: revenueID}
+
+	// Only the payer is locked, the revenue wallet is credited in place
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "10.00"})
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("6.00", walletID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), walletID, models.WITHDRAW, "4.00", "USD", "10.00", "6.00", nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("5.50", walletID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), walletID, models.FEE, "0.50", "USD", "6.00", "5.50", revenueID, sqlmock.AnyArg(), nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectQuery("UPDATE wallets SET balance = balance \\+ \\$1").
+		WithArgs("0.50", revenueID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "currency", "status"}).AddRow("100.50", "USD", "ACTIVE"))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), revenueID, models.FEE, "0.50", "USD", "100.00", "100.50", walletID, sqlmock.AnyArg(), nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	transaction, err := repo.Withdraw(context.Background(), walletID, models.NewAmount(4, 0), "USD", fee)
+	if err != nil {
+		t.Fatalf("Failed to withdraw: %v", err)
+	}
+	if fee.Transaction == nil || *fee.Transaction.ReferenceID != transaction.ID {
+		t.Errorf("Expected the fee entry to reference withdrawal %v, got %+v", transaction.ID, fee.Transaction)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Withdraw_FeeExceedsBalance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID, revenueID := uuid.New(), uuid.New()
+	fee := &models.Fee{Amount: models.NewAmount(0, 50), Currency: "USD", RevenueWalletID: revenueID}
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "10.00"})
+	mock.ExpectRollback()
+
+	// The amount alone is covered, the amount plus the fee is not
+	_, err = repo.Withdraw(context.Background(), walletID, models.NewAmount(10, 0), "USD", fee)
+	if !errors.Is(err, apperrors.ErrInsufficientFunds) {
+		t.Fatalf("Expected insufficient funds error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
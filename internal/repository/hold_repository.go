@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+	"wallet_service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	holdColumns = `id, wallet_id, amount, currency, captured_amount, status, capture_transaction_id, expires_at, created_at, updated_at`
+
+	updateHeldBalanceQuery = `UPDATE wallets SET held_balance = $1, updated_at = NOW() WHERE id = $2`
+	lockHoldQuery          = `SELECT ` + holdColumns + ` FROM holds WHERE id = $1 FOR UPDATE`
+)
+
+// CreateHold reserves hold.Amount on the wallet. The reservation fails if the
+// wallet is not active, the available balance, i.e. the balance minus other
+// active holds, plus the credit line is too small or the hold would break the
+// wallet's withdrawal limits.
+func (r *WalletRepository) CreateHold(ctx context.Context, hold *models.Hold) error {
+	ctx, tx, done, err := beginTx(ctx, r.db, "create_hold")
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	wallet, err := lockWallet(ctx, tx, hold.WalletID)
+	if err != nil {
+		return err
+	}
+	if err := wallet.checkDebit(); err != nil {
+		return err
+	}
+	if wallet.Currency != hold.Currency {
+		return apperrors.ErrCurrencyMismatch
+	}
+	if wallet.Spendable() < hold.Amount {
+		return apperrors.ErrInsufficientFunds
+	}
+	if err := r.checkLimits(ctx, tx, hold.WalletID, wallet, models.CAPTURE, hold.Amount, wallet.Balance-hold.Amount); err != nil {
+		return err
+	}
+
+	if err := updateHeldBalance(ctx, tx, hold.WalletID, wallet.HeldBalance+hold.Amount); err != nil {
+		return err
+	}
+
+	hold.Status = models.HoldActive
+	query := `INSERT INTO holds (id, wallet_id, amount, currency, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at`
+	err = tx.QueryRowxContext(ctx, query, hold.ID, hold.WalletID, hold.Amount, hold.Currency, hold.Status, hold.ExpiresAt).
+		Scan(&hold.CreatedAt, &hold.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create hold: %w", err)
+	}
+
+	if err = commit(ctx, tx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WalletRepository) GetHold(ctx context.Context, id uuid.UUID) (*models.Hold, error) {
+	var hold models.Hold
+	err := r.db.GetContext(ctx, &hold, `SELECT `+holdColumns+` FROM holds WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrHoldNotFound
+		}
+		return nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+	return &hold, nil
+}
+
+// CaptureHold debits amount from the wallet and closes the hold. A zero
+// amount captures the whole hold; whatever is not captured is released, so a
+// hold can be captured only once.
+func (r *WalletRepository) CaptureHold(ctx context.Context, id uuid.UUID, amount models.Amount) (*models.HoldCapture, error) {
+	ctx, tx, done, err := beginTx(ctx, r.db, "capture_hold")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	hold, wallet, err := lockHold(ctx, tx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if !hold.ExpiresAt.After(time.Now()) {
+		// Release the expired hold right away instead of waiting for the sweeper
+		if _, err := releaseHold(ctx, tx, hold, wallet, models.HoldExpired); err != nil {
+			return nil, err
+		}
+		if err = commit(ctx, tx); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil, apperrors.ErrHoldExpired
+	}
+
+	// The hold stays active, voiding it or letting it expire still works
+	if err := wallet.checkDebit(); err != nil {
+		return nil, err
+	}
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount > hold.Amount {
+		return nil, apperrors.ErrCaptureExceedsHold
+	}
+
+	newBalance := wallet.Balance - amount
+	if err := updateBalance(ctx, tx, hold.WalletID, newBalance); err != nil {
+		return nil, err
+	}
+	if err := updateHeldBalance(ctx, tx, hold.WalletID, wallet.HeldBalance-hold.Amount); err != nil {
+		return nil, err
+	}
+
+	entry := &models.Transaction{
+		ID:            uuid.New(),
+		WalletID:      hold.WalletID,
+		OperationType: models.CAPTURE,
+		Amount:        amount,
+		Currency:      hold.Currency,
+		BalanceBefore: wallet.Balance,
+		BalanceAfter:  newBalance,
+		ReferenceID:   &hold.ID,
+	}
+	if err := insertTransaction(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE holds SET status = $1, captured_amount = $2, capture_transaction_id = $3, updated_at = NOW()
+		WHERE id = $4 RETURNING updated_at`
+	if err := tx.GetContext(ctx, &hold.UpdatedAt, query, models.HoldCaptured, amount, entry.ID, hold.ID); err != nil {
+		return nil, fmt.Errorf("failed to capture hold: %w", err)
+	}
+	hold.Status = models.HoldCaptured
+	hold.CapturedAmount = amount
+	hold.CaptureTransactionID = &entry.ID
+
+	if err = commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &models.HoldCapture{Hold: hold, Transaction: entry}, nil
+}
+
+// VoidHold releases an active hold without moving any money.
+func (r *WalletRepository) VoidHold(ctx context.Context, id uuid.UUID) (*models.Hold, error) {
+	ctx, tx, done, err := beginTx(ctx, r.db, "void_hold")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	hold, wallet, err := lockHold(ctx, tx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if hold, err = releaseHold(ctx, tx, hold, wallet, models.HoldVoided); err != nil {
+		return nil, err
+	}
+
+	if err = commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return hold, nil
+}
+
+// ExpireHolds releases up to limit active holds whose expiry has passed and
+// returns how many were released. Each hold is released in its own
+// transaction so a single busy wallet does not hold up the others.
+func (r *WalletRepository) ExpireHolds(ctx context.Context, limit int) (int, error) {
+	var ids []uuid.UUID
+	query := `SELECT id FROM holds WHERE status = $1 AND expires_at <= NOW() ORDER BY expires_at LIMIT $2`
+	if err := r.db.SelectContext(ctx, &ids, query, models.HoldActive, limit); err != nil {
+		return 0, fmt.Errorf("failed to list expired holds: %w", err)
+	}
+
+	expired := 0
+	for _, id := range ids {
+		ok, err := r.expireHold(ctx, id)
+		if err != nil {
+			return expired, err
+		}
+		if ok {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+func (r *WalletRepository) expireHold(ctx context.Context, id uuid.UUID) (bool, error) {
+	ctx, tx, done, err := beginTx(ctx, r.db, "expire_hold")
+	if err != nil {
+		return false, err
+	}
+	defer done()
+
+	hold, wallet, err := lockHold(ctx, tx, r.db, id)
+	if err != nil {
+		// Captured or voided since it was listed
+		if errors.Is(err, apperrors.ErrHoldNotActive) {
+			return false, nil
+		}
+		return false, err
+	}
+	if hold.ExpiresAt.After(time.Now()) {
+		return false, nil
+	}
+	if _, err := releaseHold(ctx, tx, hold, wallet, models.HoldExpired); err != nil {
+		return false, err
+	}
+
+	if err = commit(ctx, tx); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// lockHold locks an active hold together with its wallet. The wallet row is
+// locked first, like every other balance change, so holds cannot deadlock
+// with concurrent operations on the same wallet.
+func lockHold(ctx context.Context, tx *sqlx.Tx, db *sqlx.DB, id uuid.UUID) (*models.Hold, *walletState, error) {
+	var walletID uuid.UUID
+	if err := db.GetContext(ctx, &walletID, `SELECT wallet_id FROM holds WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, apperrors.ErrHoldNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get hold: %w", err)
+	}
+
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var hold models.Hold
+	lockCtx, span := startStatement(ctx, "db.lock_hold", lockHoldQuery, tracing.WalletIDKey.String(walletID.String()))
+	err = tx.GetContext(lockCtx, &hold, lockHoldQuery, id)
+	tracing.End(span, err)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock hold: %w", err)
+	}
+	if hold.Status != models.HoldActive {
+		return nil, nil, apperrors.ErrHoldNotActive
+	}
+	return &hold, wallet, nil
+}
+
+// releaseHold closes an active hold with the given status and returns its
+// amount to the available balance.
+func releaseHold(ctx context.Context, tx *sqlx.Tx, hold *models.Hold, wallet *walletState, status models.HoldStatus) (*models.Hold, error) {
+	if err := updateHeldBalance(ctx, tx, hold.WalletID, wallet.HeldBalance-hold.Amount); err != nil {
+		return nil, err
+	}
+
+	query := `UPDATE holds SET status = $1, updated_at = NOW() WHERE id = $2 RETURNING updated_at`
+	if err := tx.GetContext(ctx, &hold.UpdatedAt, query, status, hold.ID); err != nil {
+		return nil, fmt.Errorf("failed to release hold: %w", err)
+	}
+	hold.Status = status
+	return hold, nil
+}
+
+func updateHeldBalance(ctx context.Context, tx *sqlx.Tx, walletID uuid.UUID, heldBalance models.Amount) (err error) {
+	ctx, span := startStatement(ctx, "db.update_held_balance", updateHeldBalanceQuery, tracing.WalletIDKey.String(walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := tx.ExecContext(ctx, updateHeldBalanceQuery, heldBalance, walletID); err != nil {
+		return fmt.Errorf("failed to update held balance: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var holdRowColumns = []string{"id", "wallet_id", "amount", "currency", "captured_amount", "status", "capture_transaction_id", "expires_at", "created_at", "updated_at"}
+
+func TestWalletRepository_Withdraw_RespectsHolds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	walletID := uuid.New()
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "100.00", Held: "60.00"})
+	mock.ExpectRollback()
+
+	_, err = repo.Withdraw(context.Background(), walletID, models.NewAmount(50, 0), "USD", nil)
+	if !errors.Is(err, apperrors.ErrInsufficientFunds) {
+		t.Fatalf("Expected insufficient funds error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_CaptureHold_Partial(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	walletID := uuid.New()
+	holdID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT wallet_id FROM holds WHERE id = \\$1").
+		WithArgs(holdID).
+		WillReturnRows(sqlmock.NewRows([]string{"wallet_id"}).AddRow(walletID))
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "100.00", Held: "40.00"})
+	mock.ExpectQuery("SELECT (.+) FROM holds WHERE id = \\$1 FOR UPDATE").
+		WithArgs(holdID).
+		WillReturnRows(sqlmock.NewRows(holdRowColumns).
+			AddRow(holdID, walletID, "30.00", "USD", "0.00", "ACTIVE", nil, now.Add(time.Hour), now, now))
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("80.00", walletID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// The uncaptured 10.00 is released together with the captured part
+	mock.ExpectExec("UPDATE wallets SET held_balance = \\$1").
+		WithArgs("10.00", walletID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), walletID, models.CAPTURE, "20.00", "USD", "100.00", "80.00", nil, holdID, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(now))
+	mock.ExpectQuery("UPDATE holds SET status = \\$1, captured_amount = \\$2").
+		WithArgs(models.HoldCaptured, "20.00", sqlmock.AnyArg(), holdID).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(now))
+	mock.ExpectCommit()
+
+	capture, err := repo.CaptureHold(context.Background(), holdID, models.NewAmount(20, 0))
+	if err != nil {
+		t.Fatalf("Failed to capture hold: %v", err)
+	}
+
+	if capture.Hold.Status != models.HoldCaptured || capture.Hold.CapturedAmount != models.NewAmount(20, 0) {
+		t.Errorf("Unexpected hold %+v", capture.Hold)
+	}
+
+	if capture.Transaction.BalanceAfter != models.NewAmount(80, 0) {
+		t.Errorf("Expected balance after 80.00, got %v", capture.Transaction.BalanceAfter)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"wallet_service/internal/models"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type IdempotencyRepositoryInterface interface {
+	Reserve(ctx context.Context, scope, key, requestHash string, lease time.Duration) (*models.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, scope, key string, status int, body []byte) error
+	Release(ctx context.Context, scope, key string) error
+	DeleteExpired(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type IdempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve claims the key for a new request for the duration of lease. The
+// primary key on (scope, key) guarantees that only one of several concurrent
+// requests wins; the others get the existing record back with created set to
+// false. A reservation whose lease ran out before it was completed was
+// abandoned, e.g. by a crashed instance, and is taken over by a retry of the
+// same request.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, scope, key, requestHash string, lease time.Duration) (*models.IdempotencyRecord, bool, error) {
+	var record models.IdempotencyRecord
+	query := `INSERT INTO idempotency_keys (scope, key, request_hash, locked_until) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (scope, key) DO UPDATE SET locked_until = EXCLUDED.locked_until, created_at = NOW()
+		WHERE idempotency_keys.response_status IS NULL AND idempotency_keys.locked_until < NOW()
+			AND idempotency_keys.request_hash = EXCLUDED.request_hash
+		RETURNING scope, key, request_hash, response_status, response_body, created_at, completed_at`
+	err := r.db.GetContext(ctx, &record, query, scope, key, requestHash, time.Now().Add(lease))
+	if err == nil {
+		return &record, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	query = `SELECT scope, key, request_hash, response_status, response_body, created_at, completed_at
+		FROM idempotency_keys WHERE scope = $1 AND key = $2`
+	if err := r.db.GetContext(ctx, &record, query, scope, key); err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return &record, false, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, status int, body []byte) error {
+	query := `UPDATE idempotency_keys SET response_status = $1, response_body = $2, completed_at = NOW()
+		WHERE scope = $3 AND key = $4`
+	if _, err := r.db.ExecContext(ctx, query, status, body, scope, key); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release drops an unfinished reservation so the client may retry with the
+// same key, e.g. after a server error.
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND response_status IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, scope, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired deletes up to limit keys reserved before the given time,
+// except reservations whose lease is still running, and returns how many it
+// deleted.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, before time.Time, limit int) (int, error) {
+	query := `DELETE FROM idempotency_keys WHERE (scope, key) IN (
+		SELECT scope, key FROM idempotency_keys
+		WHERE created_at < $1 AND (response_status IS NOT NULL OR locked_until < NOW())
+		LIMIT $2)`
+	result, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return int(deleted), nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+)
+
+var idempotencyColumns = []string{"scope", "key", "request_hash", "response_status", "response_body", "created_at", "completed_at"}
+
+func TestIdempotencyRepository_Reserve_New(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(sqlx.NewDb(db, "sqlmock"))
+
+	// Only a reservation of the same request whose lease ran out is taken over
+	mock.ExpectQuery("INSERT INTO idempotency_keys (.+) ON CONFLICT \\(scope, key\\) DO UPDATE (.+) WHERE idempotency_keys.response_status IS NULL AND idempotency_keys.locked_until < NOW\\(\\)\\s+AND idempotency_keys.request_hash = EXCLUDED.request_hash").
+		WithArgs("POST /api/v1/wallet", "key-1", "hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns).AddRow("POST /api/v1/wallet", "key-1", "hash", nil, nil, time.Now(), nil))
+
+	record, created, err := repo.Reserve(context.Background(), "POST /api/v1/wallet", "key-1", "hash", time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to reserve key: %v", err)
+	}
+
+	if !created {
+		t.Error("Expected key to be newly reserved")
+	}
+
+	if record.Completed() {
+		t.Error("Expected new reservation to be incomplete")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestIdempotencyRepository_Reserve_Existing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery("INSERT INTO idempotency_keys").
+		WithArgs("POST /api/v1/wallet", "key-1", "hash", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns))
+	mock.ExpectQuery("SELECT (.+) FROM idempotency_keys WHERE scope = \\$1 AND key = \\$2").
+		WithArgs("POST /api/v1/wallet", "key-1").
+		WillReturnRows(sqlmock.NewRows(idempotencyColumns).
+			AddRow("POST /api/v1/wallet", "key-1", "hash", 200, []byte(`{"message":"Operation successful"}`), time.Now(), time.Now()))
+
+	record, created, err := repo.Reserve(context.Background(), "POST /api/v1/wallet", "key-1", "hash", time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to reserve key: %v", err)
+	}
+
+	if created {
+		t.Error("Expected existing reservation to be returned")
+	}
+
+	if !record.Completed() || *record.ResponseStatus != 200 {
+		t.Errorf("Expected completed record with status 200, got %+v", record)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestIdempotencyRepository_DeleteExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewIdempotencyRepository(sqlx.NewDb(db, "sqlmock"))
+	before := time.Now().Add(-24 * time.Hour)
+
+	// Reservations still within their lease are kept whatever their age
+	mock.ExpectExec("DELETE FROM idempotency_keys (.+) WHERE created_at < \\$1 AND \\(response_status IS NOT NULL OR locked_until < NOW\\(\\)\\)").
+		WithArgs(before, 100).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	deleted, err := repo.DeleteExpired(context.Background(), before, 100)
+	if err != nil {
+		t.Fatalf("Failed to delete expired keys: %v", err)
+	}
+	if deleted != 42 {
+		t.Errorf("Expected 42 deleted keys, got %d", deleted)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
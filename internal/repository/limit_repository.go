@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+	"wallet_service/internal/tracing"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var errUnknownTier = apperrors.New(apperrors.CodeInvalidRequest, "unknown wallet tier")
+
+const (
+	// Withdrawals include captured holds and the holds not captured yet, so
+	// that holding and capturing cannot get around the withdrawal limits
+	withdrawalTotalsQuery = `SELECT COALESCE(SUM(amount) FILTER (WHERE created_at >= $2), 0), COALESCE(SUM(amount), 0) FROM (
+		SELECT amount, created_at FROM transactions
+		WHERE wallet_id = $1 AND operation_type IN ('WITHDRAW', 'CAPTURE') AND created_at >= $3
+		UNION ALL
+		SELECT amount, created_at FROM holds WHERE wallet_id = $1 AND status = 'ACTIVE' AND created_at >= $3
+	) debits`
+	transferTotalsQuery = `SELECT COALESCE(SUM(amount) FILTER (WHERE created_at >= $2), 0), COALESCE(SUM(amount), 0)
+		FROM transactions WHERE wallet_id = $1 AND operation_type = 'TRANSFER_OUT' AND created_at >= $3`
+)
+
+// GetWalletLimits returns the tier and overrides of the wallet and the limits
+// resulting from them.
+func (r *WalletRepository) GetWalletLimits(ctx context.Context, walletID uuid.UUID) (*models.WalletLimitsView, error) {
+	var wallet walletState
+	err := r.db.GetContext(ctx, &wallet, `SELECT tier, limits FROM wallets WHERE id = $1`, walletID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet limits: %w", err)
+	}
+	return r.limitsView(walletID, &wallet), nil
+}
+
+// SetWalletLimits moves the wallet to tier, unless it is empty, and replaces
+// its overrides. The new limits apply to operations from then on; a balance
+// already outside them is left alone.
+func (r *WalletRepository) SetWalletLimits(ctx context.Context, walletID uuid.UUID, tier string, overrides models.WalletLimits) (*models.WalletLimitsView, error) {
+	if tier != "" && !r.Limits.HasTier(tier) {
+		return nil, errUnknownTier
+	}
+	if err := overrides.Validate(); err != nil {
+		return nil, err
+	}
+
+	var wallet walletState
+	query := `UPDATE wallets SET tier = COALESCE(NULLIF($1, ''), tier), limits = $2, updated_at = NOW() WHERE id = $3 RETURNING tier, limits`
+	err := r.db.GetContext(ctx, &wallet, query, tier, overrides, walletID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to set wallet limits: %w", err)
+	}
+	return r.limitsView(walletID, &wallet), nil
+}
+
+func (r *WalletRepository) limitsView(walletID uuid.UUID, wallet *walletState) *models.WalletLimitsView {
+	return &models.WalletLimitsView{
+		WalletID:  walletID,
+		Tier:      wallet.Tier,
+		Overrides: wallet.Limits,
+		Effective: r.Limits.For(wallet.Tier, wallet.Limits),
+	}
+}
+
+// checkLimits enforces the limits of a wallet locked in tx on an operation
+// of amount that leaves it with balance. DEPOSIT and TRANSFER_IN are checked
+// as credits; WITHDRAW and TRANSFER_OUT as debits, each against its own
+// daily and monthly totals. A new hold is checked as a withdrawal, CAPTURE,
+// of the held amount, so capturing it needs no further check. Since the
+// wallet row is locked, concurrent operations cannot add to the totals until
+// tx ends.
+func (r *WalletRepository) checkLimits(ctx context.Context, tx *sqlx.Tx, walletID uuid.UUID, wallet *walletState, operationType models.OperationType, amount, balance models.Amount) error {
+	limits := r.Limits.For(wallet.Tier, wallet.Limits)
+
+	// Incoming transfers are bounded by the limits of the sender
+	if operationType != models.TRANSFER_IN && limits.MaxOperation != nil && amount > *limits.MaxOperation {
+		return apperrors.ErrOperationLimitExceeded
+	}
+
+	switch operationType {
+	case models.DEPOSIT, models.TRANSFER_IN:
+		if limits.MaxBalance != nil && balance > *limits.MaxBalance {
+			return apperrors.ErrMaxBalanceExceeded
+		}
+		return nil
+	}
+
+	if limits.MinBalance != nil && balance-wallet.HeldBalance < *limits.MinBalance {
+		return apperrors.ErrMinBalanceExceeded
+	}
+
+	daily, monthly := limits.DailyWithdrawal, limits.MonthlyWithdrawal
+	if operationType == models.TRANSFER_OUT {
+		daily, monthly = limits.DailyTransfer, limits.MonthlyTransfer
+	}
+	if daily == nil && monthly == nil {
+		return nil
+	}
+
+	dayTotal, monthTotal, err := periodTotals(ctx, tx, walletID, operationType, time.Now())
+	if err != nil {
+		return err
+	}
+	if daily != nil && dayTotal+amount > *daily {
+		return apperrors.ErrDailyLimitExceeded
+	}
+	if monthly != nil && monthTotal+amount > *monthly {
+		return apperrors.ErrMonthlyLimitExceeded
+	}
+	return nil
+}
+
+// periodTotals sums the wallet's outgoing transfers, for TRANSFER_OUT, or
+// its withdrawals otherwise, booked since midnight and since the start of
+// the month, both in UTC.
+func periodTotals(ctx context.Context, tx *sqlx.Tx, walletID uuid.UUID, operationType models.OperationType, now time.Time) (day, month models.Amount, err error) {
+	query := withdrawalTotalsQuery
+	if operationType == models.TRANSFER_OUT {
+		query = transferTotalsQuery
+	}
+	ctx, span := startStatement(ctx, "db.period_totals", query,
+		tracing.WalletIDKey.String(walletID.String()), tracing.OperationTypeKey.String(string(operationType)))
+	defer func() { tracing.End(span, err) }()
+
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	err = tx.QueryRowxContext(ctx, query, walletID, dayStart, monthStart).Scan(&day, &month)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to sum period totals: %w", err)
+	}
+	return day, month, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/limits"
+	"wallet_service/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func limitAmount(major int64) *models.Amount {
+	a := models.NewAmount(major, 0)
+	return &a
+}
+
+func newLimitPolicy(t *testing.T) *limits.Policy {
+	policy, err := limits.NewPolicy(limits.Config{
+		Default: models.WalletLimits{DailyWithdrawal: limitAmount(100), MaxBalance: limitAmount(1000)},
+		Tiers:   map[string]models.WalletLimits{"premium": {MaxOperation: limitAmount(5000), DailyWithdrawal: limitAmount(10000)}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build limit policy: %v", err)
+	}
+	return policy
+}
+
+func TestWalletRepository_Withdraw_DailyLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	repo.Limits = newLimitPolicy(t)
+
+	walletID := uuid.New()
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "500.00"})
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\) FILTER").
+		WithArgs(walletID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "month"}).AddRow("80.00", "300.00"))
+	mock.ExpectRollback()
+
+	_, err = repo.Withdraw(context.Background(), walletID, models.NewAmount(30, 0), "USD", nil)
+	if !errors.Is(err, apperrors.ErrDailyLimitExceeded) {
+		t.Fatalf("Expected daily limit error, got %v", err)
+	}
+	if apperrors.CodeOf(err) != apperrors.CodeLimitExceeded {
+		t.Errorf("Expected code %s, got %s", apperrors.CodeLimitExceeded, apperrors.CodeOf(err))
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Withdraw_WalletOverride(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	repo.Limits = newLimitPolicy(t)
+
+	// The wallet's own single operation limit is lower than its tier's
+	walletID := uuid.New()
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "500.00", Tier: "premium", Limits: `{"max_operation": 50}`})
+	mock.ExpectRollback()
+
+	_, err = repo.Withdraw(context.Background(), walletID, models.NewAmount(60, 0), "USD", nil)
+	if !errors.Is(err, apperrors.ErrOperationLimitExceeded) {
+		t.Fatalf("Expected operation limit error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Transfer_MaxBalance(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	repo.Limits = newLimitPolicy(t)
+
+	fromID, toID := uuid.New(), uuid.New()
+	balances := map[uuid.UUID]string{fromID: "500.00", toID: "950.00"}
+	mock.ExpectBegin()
+	for _, id := range orderedWalletIDs(fromID, toID) {
+		expectLockWallet(mock, id, lockedWallet{Balance: balances[id]})
+	}
+	mock.ExpectRollback()
+
+	_, err = repo.Transfer(context.Background(), fromID, toID, models.NewAmount(60, 0), "USD", nil, nil)
+	if !errors.Is(err, apperrors.ErrMaxBalanceExceeded) {
+		t.Fatalf("Expected max balance error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Withdraw_WithinLimits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	repo.Limits = newLimitPolicy(t)
+
+	walletID := uuid.New()
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "500.00", Limits: `{"min_balance": 400}`})
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\) FILTER").
+		WithArgs(walletID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "month"}).AddRow("0.00", "0.00"))
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("400.00", walletID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	// Exactly reaching the daily limit and the minimum balance is allowed
+	if _, err := repo.Withdraw(context.Background(), walletID, models.NewAmount(100, 0), "USD", nil); err != nil {
+		t.Fatalf("Failed to withdraw: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_CreateHold_WithdrawalLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	repo.Limits = newLimitPolicy(t)
+
+	walletID := uuid.New()
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "500.00"})
+	// Captures and holds awaiting capture count as withdrawals
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\) FILTER .* 'CAPTURE'.* FROM holds").
+		WithArgs(walletID, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"day", "month"}).AddRow("60.00", "60.00"))
+	mock.ExpectRollback()
+
+	hold := &models.Hold{ID: uuid.New(), WalletID: walletID, Amount: models.NewAmount(50, 0), Currency: "USD", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.CreateHold(context.Background(), hold); !errors.Is(err, apperrors.ErrDailyLimitExceeded) {
+		t.Fatalf("Expected daily limit error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_SetWalletLimits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	repo.Limits = newLimitPolicy(t)
+
+	walletID := uuid.New()
+	overrides := models.WalletLimits{DailyWithdrawal: limitAmount(20)}
+	if _, err := repo.SetWalletLimits(context.Background(), walletID, "gold", overrides); apperrors.CodeOf(err) != apperrors.CodeInvalidRequest {
+		t.Fatalf("Expected invalid request for an unknown tier, got %v", err)
+	}
+
+	mock.ExpectQuery("UPDATE wallets SET tier = COALESCE\\(NULLIF\\(\\$1, ''\\), tier\\), limits = \\$2").
+		WithArgs("premium", sqlmock.AnyArg(), walletID).
+		WillReturnRows(sqlmock.NewRows([]string{"tier", "limits"}).AddRow("premium", `{"daily_withdrawal": 20}`))
+
+	view, err := repo.SetWalletLimits(context.Background(), walletID, "premium", overrides)
+	if err != nil {
+		t.Fatalf("Failed to set limits: %v", err)
+	}
+	if *view.Effective.MaxOperation != models.NewAmount(5000, 0) || *view.Effective.DailyWithdrawal != models.NewAmount(20, 0) {
+		t.Errorf("Unexpected effective limits: %+v", view.Effective)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
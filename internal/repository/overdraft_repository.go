@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const overdraftPeriodColumns = `id, wallet_id, started_at, ended_at, lowest_balance`
+
+// SetCreditLimit sets how far below zero the wallet may go; zero turns
+// overdraft off. The limit cannot be lowered below what the wallet already
+// owes, holds included.
+func (r *WalletRepository) SetCreditLimit(ctx context.Context, walletID uuid.UUID, creditLimit models.Amount) (*models.Wallet, error) {
+	ctx, tx, done, err := beginTx(ctx, r.db, "set_credit_limit")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	state, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Status == models.WalletClosed {
+		return nil, apperrors.ErrWalletClosed
+	}
+	if state.Available() < -creditLimit {
+		return nil, apperrors.ErrCreditLimitInUse
+	}
+
+	var wallet models.Wallet
+	query := `UPDATE wallets SET credit_limit = $1, updated_at = NOW() WHERE id = $2 RETURNING ` + walletColumns
+	if err := tx.GetContext(ctx, &wallet, query, creditLimit, walletID); err != nil {
+		return nil, fmt.Errorf("failed to set credit limit: %w", err)
+	}
+
+	if err = commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &wallet, nil
+}
+
+// ListOverdraftPeriods returns the periods the wallet was overdrawn, oldest
+// first. They are recorded by a trigger on the wallet balance.
+func (r *WalletRepository) ListOverdraftPeriods(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftPeriod, error) {
+	periods := []models.OverdraftPeriod{}
+	query := `SELECT ` + overdraftPeriodColumns + ` FROM overdraft_periods WHERE wallet_id = $1 ORDER BY started_at, id`
+	if err := r.db.SelectContext(ctx, &periods, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to list overdraft periods: %w", err)
+	}
+	return periods, nil
+}
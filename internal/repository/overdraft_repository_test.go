@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestWalletRepository_Withdraw_Overdraft(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID := uuid.New()
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "10.00", CreditLimit: "50.00"})
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("-30.00", walletID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), walletID, models.WITHDRAW, "40.00", "USD", "10.00", "-30.00", nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	transaction, err := repo.Withdraw(context.Background(), walletID, models.NewAmount(40, 0), "USD", nil)
+	if err != nil {
+		t.Fatalf("Failed to withdraw into overdraft: %v", err)
+	}
+	if transaction.BalanceAfter != models.NewAmount(-30, 0) {
+		t.Errorf("Expected balance -30.00, got %v", transaction.BalanceAfter)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Withdraw_BelowCreditLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	// Holds count against the credit line too
+	walletID := uuid.New()
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "10.00", Held: "5.00", CreditLimit: "50.00"})
+	mock.ExpectRollback()
+
+	_, err = repo.Withdraw(context.Background(), walletID, models.NewAmount(56, 0), "USD", nil)
+	if !errors.Is(err, apperrors.ErrInsufficientFunds) {
+		t.Fatalf("Expected insufficient funds error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_SetCreditLimit_InUse(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID := uuid.New()
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "-30.00", CreditLimit: "50.00"})
+	mock.ExpectRollback()
+
+	_, err = repo.SetCreditLimit(context.Background(), walletID, models.NewAmount(20, 0))
+	if !errors.Is(err, apperrors.ErrCreditLimitInUse) {
+		t.Fatalf("Expected credit limit in use error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_SetCreditLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID := uuid.New()
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "-30.00", CreditLimit: "50.00"})
+	mock.ExpectQuery("UPDATE wallets SET credit_limit = \\$1, updated_at = NOW\\(\\) WHERE id = \\$2 RETURNING").
+		WithArgs("30.00", walletID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "credit_limit", "currency"}).AddRow(walletID, "-30.00", "30.00", "USD"))
+	mock.ExpectCommit()
+
+	wallet, err := repo.SetCreditLimit(context.Background(), walletID, models.NewAmount(30, 0))
+	if err != nil {
+		t.Fatalf("Failed to set credit limit: %v", err)
+	}
+	if wallet.CreditLimit != models.NewAmount(30, 0) {
+		t.Errorf("Expected credit limit 30.00, got %v", wallet.CreditLimit)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// whereClause collects the conditions of a listing query together with
+// their arguments, numbering the placeholders as it goes.
+type whereClause struct {
+	conditions []string
+	args       []interface{}
+}
+
+// add appends a condition. Each $%d in format is replaced with the
+// placeholder of the corresponding value.
+func (w *whereClause) add(format string, values ...interface{}) {
+	placeholders := make([]interface{}, len(values))
+	for i, value := range values {
+		w.args = append(w.args, value)
+		placeholders[i] = len(w.args)
+	}
+	w.conditions = append(w.conditions, fmt.Sprintf(format, placeholders...))
+}
+
+// addIn appends "column IN (...)" matching any of values.
+func (w *whereClause) addIn(column string, values []interface{}) {
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		w.args = append(w.args, value)
+		placeholders[i] = fmt.Sprintf("$%d", len(w.args))
+	}
+	w.conditions = append(w.conditions, column+" IN ("+strings.Join(placeholders, ", ")+")")
+}
+
+// limit adds the LIMIT argument and returns its placeholder number.
+func (w *whereClause) limit(n int) int {
+	w.args = append(w.args, n)
+	return len(w.args)
+}
+
+func (w *whereClause) String() string {
+	if len(w.conditions) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(w.conditions, " AND ")
+}
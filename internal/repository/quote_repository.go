@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const quoteColumns = `id, from_wallet_id, to_wallet_id, source_amount, source_currency, destination_amount, destination_currency,
+	rate, rounding_adjustment, expires_at, used_at, created_at`
+
+func (r *WalletRepository) CreateQuote(ctx context.Context, quote *models.Quote) error {
+	query := `INSERT INTO fx_quotes (id, from_wallet_id, to_wallet_id, source_amount, source_currency, destination_amount, destination_currency,
+		rate, rounding_adjustment, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING created_at`
+	err := r.db.GetContext(ctx, &quote.CreatedAt, query,
+		quote.ID, quote.FromWalletID, quote.ToWalletID, quote.SourceAmount, quote.SourceCurrency,
+		quote.DestinationAmount, quote.DestinationCurrency, quote.Rate, quote.RoundingAdjustment, quote.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create quote: %w", err)
+	}
+	return nil
+}
+
+func (r *WalletRepository) GetQuote(ctx context.Context, id uuid.UUID) (*models.Quote, error) {
+	var quote models.Quote
+	err := r.db.GetContext(ctx, &quote, `SELECT `+quoteColumns+` FROM fx_quotes WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrQuoteNotFound
+		}
+		return nil, fmt.Errorf("failed to get quote: %w", err)
+	}
+	return &quote, nil
+}
+
+// consumeQuote marks the quote as used. Doing this inside the transfer
+// transaction guarantees that a quote is honoured at most once and only
+// before it expires.
+func consumeQuote(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) error {
+	query := `UPDATE fx_quotes SET used_at = NOW() WHERE id = $1 AND used_at IS NULL AND expires_at > NOW()`
+	result, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to use quote: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return apperrors.ErrQuoteExpired
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var errReversalTooSmall = apperrors.New(apperrors.CodeInvalidAmount, "reversal amount rounds to zero on the other leg")
+
+func (r *WalletRepository) GetTransaction(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.GetContext(ctx, &transaction, `SELECT `+transactionColumns+` FROM transactions WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return &transaction, nil
+}
+
+// ReverseTransaction books entries compensating amount of the transaction, or
+// everything not reversed yet when amount is zero. A transfer is reversed on
+// both legs in one database transaction; for cross-currency transfers the
+// other leg is reversed in proportion, and in full once the given leg is.
+// Fees are not refundable: the FEE entries of the operation are left alone,
+// even by a full reversal, and cannot be reversed on their own.
+func (r *WalletRepository) ReverseTransaction(ctx context.Context, id uuid.UUID, amount models.Amount) (*models.Reversal, error) {
+	ctx, tx, done, err := beginTx(ctx, r.db, "reverse")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	legs, err := reversibleLegs(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reversals of a wallet are serialised by its row lock, so the amounts
+	// already reversed cannot change until the transaction commits
+	walletIDs := []uuid.UUID{legs[0].WalletID}
+	if len(legs) == 2 {
+		walletIDs = orderedWalletIDs(legs[0].WalletID, legs[1].WalletID)
+	}
+	wallets := make(map[uuid.UUID]*walletState, len(walletIDs))
+	for _, walletID := range walletIDs {
+		wallet, err := lockWallet(ctx, tx, walletID)
+		if err != nil {
+			return nil, err
+		}
+		wallets[walletID] = wallet
+	}
+
+	remaining := make([]models.Amount, len(legs))
+	for i, leg := range legs {
+		var reversed models.Amount
+		query := `SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE reversed_transaction_id = $1`
+		if err := tx.GetContext(ctx, &reversed, query, leg.ID); err != nil {
+			return nil, fmt.Errorf("failed to sum reversals: %w", err)
+		}
+		remaining[i] = leg.Amount - reversed
+	}
+
+	if amount == 0 {
+		amount = remaining[0]
+	}
+	if amount == 0 || amount > remaining[0] {
+		return nil, apperrors.ErrReversalExceedsAmount
+	}
+	amounts := []models.Amount{amount}
+	if len(legs) == 2 {
+		other := remaining[1]
+		if amount < remaining[0] {
+			other = min(proportionalAmount(legs[1].Amount, amount, legs[0].Amount, legs[1].Currency), remaining[1])
+		}
+		if other <= 0 {
+			return nil, errReversalTooSmall
+		}
+		amounts = append(amounts, other)
+	}
+
+	reversal := &models.Reversal{
+		ID:              uuid.New(),
+		TransactionID:   id,
+		Amount:          amount,
+		Currency:        legs[0].Currency,
+		RemainingAmount: remaining[0] - amount,
+	}
+	for i, leg := range legs {
+		wallet := wallets[leg.WalletID]
+		entry := &models.Transaction{
+			ID:                    uuid.New(),
+			WalletID:              leg.WalletID,
+			OperationType:         models.REVERSAL,
+			Amount:                amounts[i],
+			Currency:              leg.Currency,
+			BalanceBefore:         wallet.Balance,
+			CounterpartyWalletID:  leg.CounterpartyWalletID,
+			ReferenceID:           &reversal.ID,
+			ExchangeRate:          leg.ExchangeRate,
+			ReversedTransactionID: &leg.ID,
+		}
+		if leg.ExchangeRate != nil {
+			j := len(legs) - 1 - i
+			entry.CounterpartyAmount, entry.CounterpartyCurrency = &amounts[j], &legs[j].Currency
+		}
+
+		// Money goes back the way it came
+		if leg.BalanceAfter < leg.BalanceBefore {
+			if err := wallet.checkCredit(r.FrozenDeposits); err != nil {
+				return nil, err
+			}
+			entry.BalanceAfter = wallet.Balance + entry.Amount
+		} else {
+			if err := wallet.checkDebit(); err != nil {
+				return nil, err
+			}
+			if wallet.Spendable() < entry.Amount {
+				return nil, apperrors.ErrInsufficientFunds
+			}
+			entry.BalanceAfter = wallet.Balance - entry.Amount
+		}
+
+		if err := updateBalance(ctx, tx, leg.WalletID, entry.BalanceAfter); err != nil {
+			return nil, err
+		}
+		if err := insertTransaction(ctx, tx, entry); err != nil {
+			return nil, err
+		}
+		wallet.Balance = entry.BalanceAfter
+		reversal.Transactions = append(reversal.Transactions, *entry)
+	}
+	reversal.CreatedAt = reversal.Transactions[0].CreatedAt
+
+	if err = commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return reversal, nil
+}
+
+// reversibleLegs returns the transaction followed, for transfers, by the
+// other leg of the transfer.
+func reversibleLegs(ctx context.Context, tx *sqlx.Tx, id uuid.UUID) ([]models.Transaction, error) {
+	var transaction models.Transaction
+	err := tx.GetContext(ctx, &transaction, `SELECT `+transactionColumns+` FROM transactions WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	switch transaction.OperationType {
+	case models.DEPOSIT, models.WITHDRAW, models.CAPTURE:
+		return []models.Transaction{transaction}, nil
+	case models.TRANSFER_IN, models.TRANSFER_OUT:
+	default:
+		// Adjustments are corrected by another adjustment, reversals by
+		// reversing less in the first place; fees are kept
+		return nil, apperrors.ErrNotReversible
+	}
+
+	otherType := models.TRANSFER_IN
+	if transaction.OperationType == models.TRANSFER_IN {
+		otherType = models.TRANSFER_OUT
+	}
+	var other models.Transaction
+	query := `SELECT ` + transactionColumns + ` FROM transactions WHERE reference_id = $1 AND operation_type = $2`
+	if err := tx.GetContext(ctx, &other, query, transaction.ReferenceID, otherType); err != nil {
+		return nil, fmt.Errorf("failed to get transfer leg: %w", err)
+	}
+	return []models.Transaction{transaction, other}, nil
+}
+
+// proportionalAmount returns total*part/whole rounded half-up to the minor
+// unit of currency.
+func proportionalAmount(total, part, whole models.Amount, currency models.Currency) models.Amount {
+	unit := int64(1)
+	for i := currency.Exponent(); i < models.AmountScale; i++ {
+		unit *= 10
+	}
+	scaled := big.NewRat(total.MinorUnits(), unit)
+	scaled.Mul(scaled, big.NewRat(part.MinorUnits(), whole.MinorUnits()))
+	scaled.Add(scaled, big.NewRat(1, 2))
+	rounded := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+	return models.Amount(rounded.Int64() * unit)
+}
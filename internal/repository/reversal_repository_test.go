@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var reversalColumns = []string{"id", "wallet_id", "operation_type", "amount", "currency", "balance_before", "balance_after",
+	"counterparty_wallet_id", "reference_id", "exchange_rate", "counterparty_amount", "counterparty_currency", "created_at"}
+
+func TestWalletRepository_ReverseTransaction_PartialDeposit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID, depositID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM transactions WHERE id = \\$1").
+		WithArgs(depositID).
+		WillReturnRows(sqlmock.NewRows(reversalColumns).
+			AddRow(depositID, walletID, "DEPOSIT", "10.00", "USD", "0.00", "10.00", nil, nil, nil, nil, nil, time.Now()))
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "10.00"})
+	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\), 0\\) FROM transactions WHERE reversed_transaction_id = \\$1").
+		WithArgs(depositID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("4.00"))
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("7.00", walletID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), walletID, models.REVERSAL, "3.00", "USD", "10.00", "7.00", nil, sqlmock.AnyArg(), nil, nil, nil, nil, depositID).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	reversal, err := repo.ReverseTransaction(context.Background(), depositID, models.NewAmount(3, 0))
+	if err != nil {
+		t.Fatalf("Failed to reverse deposit: %v", err)
+	}
+	if reversal.RemainingAmount != models.NewAmount(3, 0) {
+		t.Errorf("Expected 3.00 left to reverse, got %v", reversal.RemainingAmount)
+	}
+
+	// Only 3.00 of the deposit is left after 4.00 and 3.00 were reversed
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM transactions WHERE id = \\$1").
+		WithArgs(depositID).
+		WillReturnRows(sqlmock.NewRows(reversalColumns).
+			AddRow(depositID, walletID, "DEPOSIT", "10.00", "USD", "0.00", "10.00", nil, nil, nil, nil, nil, time.Now()))
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "7.00"})
+	mock.ExpectQuery("SELECT COALESCE").
+		WithArgs(depositID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("7.00"))
+	mock.ExpectRollback()
+
+	_, err = repo.ReverseTransaction(context.Background(), depositID, models.NewAmount(3, 1))
+	if !errors.Is(err, apperrors.ErrReversalExceedsAmount) {
+		t.Errorf("Expected reversal exceeds amount error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_ReverseTransaction_CrossCurrencyTransfer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	fromID, toID := uuid.New(), uuid.New()
+	outID, inID, transferID := uuid.New(), uuid.New(), uuid.New()
+	balances := map[uuid.UUID][2]string{fromID: {"0.00", "USD"}, toID: {"9.00", "EUR"}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM transactions WHERE id = \\$1").
+		WithArgs(outID).
+		WillReturnRows(sqlmock.NewRows(reversalColumns).
+			AddRow(outID, fromID, "TRANSFER_OUT", "10.00", "USD", "10.00", "0.00", toID, transferID, "0.9", "9.00", "EUR", time.Now()))
+	mock.ExpectQuery("SELECT (.+) FROM transactions WHERE reference_id = \\$1 AND operation_type = \\$2").
+		WithArgs(transferID, models.TRANSFER_IN).
+		WillReturnRows(sqlmock.NewRows(reversalColumns).
+			AddRow(inID, toID, "TRANSFER_IN", "9.00", "EUR", "0.00", "9.00", fromID, transferID, "0.9", "10.00", "USD", time.Now()))
+	for _, id := range orderedWalletIDs(fromID, toID) {
+		expectLockWallet(mock, id, lockedWallet{Balance: balances[id][0], Currency: balances[id][1]})
+	}
+	for _, id := range []uuid.UUID{outID, inID} {
+		mock.ExpectQuery("SELECT COALESCE").
+			WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("0.00"))
+	}
+	// Reversing a third of the source amount takes back a third of the credit
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("3.33", fromID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), fromID, models.REVERSAL, "3.33", "USD", "0.00", "3.33", toID, sqlmock.AnyArg(), "0.9", "3.00", "EUR", nil, outID).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("6.00", toID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), toID, models.REVERSAL, "3.00", "EUR", "9.00", "6.00", fromID, sqlmock.AnyArg(), "0.9", "3.33", "USD", nil, inID).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	reversal, err := repo.ReverseTransaction(context.Background(), outID, models.NewAmount(3, 33))
+	if err != nil {
+		t.Fatalf("Failed to reverse transfer: %v", err)
+	}
+	if len(reversal.Transactions) != 2 {
+		t.Fatalf("Expected both legs to be reversed, got %d entries", len(reversal.Transactions))
+	}
+	if *reversal.Transactions[0].ReferenceID != reversal.ID || *reversal.Transactions[1].ReferenceID != reversal.ID {
+		t.Errorf("Expected both entries to reference reversal %v", reversal.ID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_ReverseTransaction_KeepsFee(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID, withdrawalID, feeID := uuid.New(), uuid.New(), uuid.New()
+
+	// The withdrawal of 10.00 was charged 1.00; a full reversal returns 10.00
+	// and books nothing for the fee
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM transactions WHERE id = \\$1").
+		WithArgs(withdrawalID).
+		WillReturnRows(sqlmock.NewRows(reversalColumns).
+			AddRow(withdrawalID, walletID, "WITHDRAW", "10.00", "USD", "20.00", "10.00", nil, nil, nil, nil, nil, time.Now()))
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "9.00"})
+	mock.ExpectQuery("SELECT COALESCE").
+		WithArgs(withdrawalID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow("0.00"))
+	mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+		WithArgs("19.00", walletID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), walletID, models.REVERSAL, "10.00", "USD", "9.00", "19.00", nil, sqlmock.AnyArg(), nil, nil, nil, nil, withdrawalID).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	reversal, err := repo.ReverseTransaction(context.Background(), withdrawalID, 0)
+	if err != nil {
+		t.Fatalf("Failed to reverse withdrawal: %v", err)
+	}
+	if len(reversal.Transactions) != 1 || reversal.RemainingAmount != 0 {
+		t.Errorf("Expected a single full reversal entry, got %+v", reversal)
+	}
+
+	// The fee itself cannot be reversed either
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM transactions WHERE id = \\$1").
+		WithArgs(feeID).
+		WillReturnRows(sqlmock.NewRows(reversalColumns).
+			AddRow(feeID, walletID, "FEE", "1.00", "USD", "10.00", "9.00", nil, withdrawalID, nil, nil, nil, time.Now()))
+	mock.ExpectRollback()
+
+	if _, err := repo.ReverseTransaction(context.Background(), feeID, 0); !errors.Is(err, apperrors.ErrNotReversible) {
+		t.Errorf("Expected not reversible error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestProportionalAmount(t *testing.T) {
+	tests := []struct {
+		total, part, whole models.Amount
+		currency           models.Currency
+		expected           models.Amount
+	}{
+		{models.NewAmount(9, 0), models.NewAmount(3, 33), models.NewAmount(10, 0), "EUR", models.NewAmount(3, 0)},
+		{models.NewAmount(9, 0), models.NewAmount(0, 5), models.NewAmount(10, 0), "EUR", models.NewAmount(0, 5)},
+		{models.NewAmount(1500, 0), models.NewAmount(5, 0), models.NewAmount(10, 0), "JPY", models.NewAmount(750, 0)},
+		{models.NewAmount(1501, 0), models.NewAmount(5, 0), models.NewAmount(10, 0), "JPY", models.NewAmount(751, 0)},
+	}
+
+	for _, tt := range tests {
+		if got := proportionalAmount(tt.total, tt.part, tt.whole, tt.currency); got != tt.expected {
+			t.Errorf("proportionalAmount(%v, %v, %v, %s) = %v, expected %v", tt.total, tt.part, tt.whole, tt.currency, got, tt.expected)
+		}
+	}
+}
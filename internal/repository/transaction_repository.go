@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const transactionColumns = `id, wallet_id, operation_type, amount, currency, balance_before, balance_after, counterparty_wallet_id, reference_id,
+	exchange_rate, counterparty_amount, counterparty_currency, rounding_adjustment, reversed_transaction_id, created_at`
+
+// ListTransactions returns one page of the wallet ledger. Pagination is
+// keyset based: the cursor carries the sort value and ID of the last row
+// returned, so pages stay stable while new transactions are appended.
+func (r *WalletRepository) ListTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) (*models.TransactionPage, error) {
+	sortColumn := "created_at"
+	if filter.SortBy == models.SortByAmount {
+		sortColumn = "amount"
+	}
+	direction, comparison := "DESC", "<"
+	if filter.Order == models.SortAsc {
+		direction, comparison = "ASC", ">"
+	}
+
+	var where whereClause
+	where.add("wallet_id = $%d", walletID)
+	if len(filter.OperationTypes) > 0 {
+		values := make([]interface{}, len(filter.OperationTypes))
+		for i, operationType := range filter.OperationTypes {
+			values[i] = operationType
+		}
+		where.addIn("operation_type", values)
+	}
+	if filter.MinAmount != nil {
+		where.add("amount >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		where.add("amount <= $%d", *filter.MaxAmount)
+	}
+	if filter.From != nil {
+		where.add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		where.add("created_at < $%d", *filter.To)
+	}
+	if filter.Cursor != nil {
+		value, err := cursorValue(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where.add("("+sortColumn+", id) "+comparison+" ($%d, $%d)", value, filter.Cursor.ID)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > models.MaxTransactionPageSize {
+		limit = models.DefaultTransactionPageSize
+	}
+	// Fetch one extra row to find out whether there is a next page
+	limitArg := where.limit(limit + 1)
+
+	query := fmt.Sprintf(`SELECT %s FROM transactions WHERE %s ORDER BY %s %s, id %s LIMIT $%d`,
+		transactionColumns, where.String(), sortColumn, direction, direction, limitArg)
+
+	transactions := []models.Transaction{}
+	if err := r.db.SelectContext(ctx, &transactions, query, where.args...); err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	page := &models.TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		page.NextCursor = filter.CursorFor(page.Transactions[limit-1]).Encode()
+	}
+
+	return page, nil
+}
+
+func cursorValue(cursor *models.TransactionCursor) (interface{}, error) {
+	if cursor.SortBy == models.SortByAmount {
+		amount, err := models.ParseAmount(cursor.Value)
+		if err != nil {
+			return nil, models.ErrInvalidCursor
+		}
+		return amount, nil
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	return createdAt, nil
+}
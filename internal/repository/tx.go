@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"wallet_service/internal/lock"
+	"wallet_service/internal/metrics"
+	"wallet_service/internal/tracing"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// beginTx starts the transaction of the named operation and takes the locks
+// the service's locker attached to ctx. The returned context carries the
+// span of the transaction and must be used for its statements. The returned
+// function must be deferred: it rolls the transaction back unless it was
+// committed and records how long the transaction was open.
+func beginTx(ctx context.Context, db *sqlx.DB, operation string) (context.Context, *sqlx.Tx, func(), error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "db.transaction "+operation, attribute.String("db.operation.name", operation))
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to begin transaction: %w", err)
+		tracing.End(span, err)
+		return nil, nil, nil, err
+	}
+
+	if hook := lock.TxHookFrom(ctx); hook != nil {
+		lockCtx, lockSpan := tracing.Start(ctx, "db.wallet_lock")
+		err = hook(lockCtx, tx)
+		tracing.End(lockSpan, err)
+		if err != nil {
+			tx.Rollback()
+			tracing.End(span, err)
+			return nil, nil, nil, err
+		}
+	}
+
+	done := func() {
+		outcome := "rollback"
+		if err := tx.Rollback(); errors.Is(err, sql.ErrTxDone) {
+			outcome = "commit"
+		}
+		duration := time.Since(start)
+		metrics.DBTransactionDuration.WithLabelValues(operation, outcome).Observe(duration.Seconds())
+		slog.DebugContext(ctx, "Database transaction finished",
+			"operation", operation, "outcome", outcome, "duration_ms", duration.Milliseconds())
+		span.SetAttributes(attribute.String("db.transaction.outcome", outcome))
+		span.End()
+	}
+	return ctx, tx, done, nil
+}
+
+// commit commits tx within its own span, so that the time spent flushing
+// the transaction shows up separately from its statements.
+func commit(ctx context.Context, tx *sqlx.Tx) error {
+	_, span := tracing.Start(ctx, "db.commit")
+	err := tx.Commit()
+	tracing.End(span, err)
+	return err
+}
+
+// startStatement starts the span of a single SQL statement.
+func startStatement(ctx context.Context, name, query string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("db.system", "postgresql"), attribute.String("db.query.text", query))
+	return tracing.Start(ctx, name, attrs...)
+}
@@ -1,41 +1,136 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/limits"
 	"wallet_service/internal/models"
+	"wallet_service/internal/tracing"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type WalletRepositoryInterface interface {
-	CreateWallet() (*models.Wallet, error)
-	GetWalletByID(id uuid.UUID) (*models.Wallet, error)
-	UpdateWalletBalance(id uuid.UUID, newBalance float64) error
-	Deposit(walletID uuid.UUID, amount float64) error
-	Withdraw(walletID uuid.UUID, amount float64) error
-	PerformOperation(walletID uuid.UUID, operationType models.OperationType, amount float64) error
-	Transfer(fromWalletID, toWalletID uuid.UUID, amount float64) error
+	CreateWallet(ctx context.Context, currency models.Currency, ownerID *string) (*models.Wallet, error)
+	GetWalletByID(ctx context.Context, id uuid.UUID) (*models.Wallet, error)
+	GetWallets(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Wallet, error)
+	ListWallets(ctx context.Context, filter models.WalletFilter) (*models.WalletPage, error)
+	UpdateWalletBalance(ctx context.Context, id uuid.UUID, newBalance models.Amount) error
+	Deposit(ctx context.Context, walletID uuid.UUID, amount models.Amount, currency models.Currency) (*models.Transaction, error)
+	Withdraw(ctx context.Context, walletID uuid.UUID, amount models.Amount, currency models.Currency, fee *models.Fee) (*models.Transaction, error)
+	PerformOperation(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount models.Amount, currency models.Currency, fee *models.Fee) (*models.Transaction, error)
+	Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount models.Amount, currency models.Currency, conversion *models.CurrencyConversion, fee *models.Fee) (*models.Transfer, error)
+	ExecuteBatch(ctx context.Context, ops []BatchOperation) ([]models.BatchItemResult, error)
+	ListTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) (*models.TransactionPage, error)
+	GetTransaction(ctx context.Context, id uuid.UUID) (*models.Transaction, error)
+	ReverseTransaction(ctx context.Context, id uuid.UUID, amount models.Amount) (*models.Reversal, error)
+	CreateQuote(ctx context.Context, quote *models.Quote) error
+	GetQuote(ctx context.Context, id uuid.UUID) (*models.Quote, error)
+	CreateHold(ctx context.Context, hold *models.Hold) error
+	GetHold(ctx context.Context, id uuid.UUID) (*models.Hold, error)
+	CaptureHold(ctx context.Context, id uuid.UUID, amount models.Amount) (*models.HoldCapture, error)
+	VoidHold(ctx context.Context, id uuid.UUID) (*models.Hold, error)
+	ExpireHolds(ctx context.Context, limit int) (int, error)
+	ChangeWalletStatus(ctx context.Context, walletID uuid.UUID, status models.WalletStatus, reason, changedBy string, admin bool) (*models.WalletStatusChange, error)
+	ListWalletStatusChanges(ctx context.Context, walletID uuid.UUID) ([]models.WalletStatusChange, error)
+	SetCreditLimit(ctx context.Context, walletID uuid.UUID, creditLimit models.Amount) (*models.Wallet, error)
+	ListOverdraftPeriods(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftPeriod, error)
+	GetWalletLimits(ctx context.Context, walletID uuid.UUID) (*models.WalletLimitsView, error)
+	SetWalletLimits(ctx context.Context, walletID uuid.UUID, tier string, overrides models.WalletLimits) (*models.WalletLimitsView, error)
+}
+
+const (
+	walletColumns      = `id, owner_id, status, tier, balance, held_balance, balance - held_balance AS available_balance, credit_limit, currency, created_at, updated_at`
+	lockWalletQuery    = `SELECT balance, held_balance, credit_limit, currency, status, tier, limits FROM wallets WHERE id = $1 FOR UPDATE`
+	updateBalanceQuery = `UPDATE wallets SET balance = $1, updated_at = NOW() WHERE id = $2`
+	insertTxQuery      = `INSERT INTO transactions (id, wallet_id, operation_type, amount, currency, balance_before, balance_after, counterparty_wallet_id, reference_id,
+		exchange_rate, counterparty_amount, counterparty_currency, rounding_adjustment, reversed_transaction_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING created_at`
+)
+
+// walletState is the part of a wallet row read while holding its row lock.
+type walletState struct {
+	Balance     models.Amount       `db:"balance"`
+	HeldBalance models.Amount       `db:"held_balance"`
+	CreditLimit models.Amount       `db:"credit_limit"`
+	Currency    models.Currency     `db:"currency"`
+	Status      models.WalletStatus `db:"status"`
+	Tier        string              `db:"tier"`
+	Limits      models.WalletLimits `db:"limits"`
+}
+
+// Available returns the part of the balance not reserved by holds.
+func (w *walletState) Available() models.Amount {
+	return w.Balance - w.HeldBalance
+}
+
+// Spendable returns how much can still be debited: the available balance
+// plus the credit line. The wallet may not go below -CreditLimit.
+func (w *walletState) Spendable() models.Amount {
+	return w.Available() + w.CreditLimit
+}
+
+// checkDebit returns why money cannot leave the wallet, if it cannot.
+func (w *walletState) checkDebit() error {
+	switch w.Status {
+	case models.WalletFrozen:
+		return apperrors.ErrWalletFrozen
+	case models.WalletClosed:
+		return apperrors.ErrWalletClosed
+	}
+	return nil
+}
+
+// checkCredit returns why money cannot enter the wallet, if it cannot.
+// Frozen wallets are credited only if frozenCredits is set.
+func (w *walletState) checkCredit(frozenCredits bool) error {
+	switch w.Status {
+	case models.WalletFrozen:
+		if !frozenCredits {
+			return apperrors.ErrWalletFrozen
+		}
+	case models.WalletClosed:
+		return apperrors.ErrWalletClosed
+	}
+	return nil
 }
 
 type WalletRepository struct {
 	db *sqlx.DB
+	// FrozenDeposits lets frozen wallets receive deposits and incoming
+	// transfers. Debits are always rejected while a wallet is frozen.
+	FrozenDeposits bool
+	// Limits holds the global and tier limits, see checkLimits. Per-wallet
+	// overrides apply even when it is nil.
+	Limits *limits.Policy
 }
 
 func NewWalletRepository(db *sqlx.DB) *WalletRepository {
 	return &WalletRepository{db: db}
 }
 
-func (r *WalletRepository) CreateWallet() (*models.Wallet, error) {
+func (r *WalletRepository) CreateWallet(ctx context.Context, currency models.Currency, ownerID *string) (*models.Wallet, error) {
 	wallet := &models.Wallet{
-		ID:      uuid.New(),
-		Balance: 0.0,
+		ID:       uuid.New(),
+		OwnerID:  ownerID,
+		Status:   models.WalletActive,
+		Tier:     models.DefaultWalletTier,
+		Balance:  0,
+		Currency: currency,
 	}
 
-	query := `INSERT INTO wallets (id, balance) VALUES ($1, $2)`
-	_, err := r.db.Exec(query, wallet.ID, wallet.Balance)
+	query := `INSERT INTO wallets (id, owner_id, status, balance, currency) VALUES ($1, $2, $3, $4, $5) RETURNING created_at, updated_at`
+	err := r.db.QueryRowxContext(ctx, query, wallet.ID, wallet.OwnerID, wallet.Status, wallet.Balance, wallet.Currency).Scan(&wallet.CreatedAt, &wallet.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create wallet: %w", err)
 	}
@@ -43,174 +138,523 @@ func (r *WalletRepository) CreateWallet() (*models.Wallet, error) {
 	return wallet, nil
 }
 
-func (r *WalletRepository) GetWalletByID(id uuid.UUID) (*models.Wallet, error) {
+func (r *WalletRepository) GetWalletByID(ctx context.Context, id uuid.UUID) (*models.Wallet, error) {
 	var wallet models.Wallet
-	query := `SELECT id, balance, created_at, updated_at FROM wallets WHERE id = $1`
-	err := r.db.Get(&wallet, query, id)
+	query := `SELECT ` + walletColumns + ` FROM wallets WHERE id = $1`
+	err := r.db.GetContext(ctx, &wallet, query, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("wallet not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrWalletNotFound
 		}
 		return nil, fmt.Errorf("failed to get wallet: %w", err)
 	}
 	return &wallet, nil
 }
 
-func (r *WalletRepository) UpdateWalletBalance(id uuid.UUID, newBalance float64) error {
-	query := `UPDATE wallets SET balance = $1, updated_at = NOW() WHERE id = $2`
-	result, err := r.db.Exec(query, newBalance, id)
-	if err != nil {
-		return fmt.Errorf("failed to update wallet balance: %w", err)
+// GetWallets returns the existing wallets among ids by ID, in a single query.
+func (r *WalletRepository) GetWallets(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Wallet, error) {
+	keys := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		keys[i] = id.String()
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+	var found []models.Wallet
+	query := `SELECT ` + walletColumns + ` FROM wallets WHERE id = ANY($1::uuid[])`
+	if err := r.db.SelectContext(ctx, &found, query, keys); err != nil {
+		return nil, fmt.Errorf("failed to get wallets: %w", err)
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("wallet not found")
+
+	wallets := make(map[uuid.UUID]*models.Wallet, len(found))
+	for i := range found {
+		wallets[found[i].ID] = &found[i]
 	}
+	return wallets, nil
+}
 
-	return nil
+// ListWallets returns one page of wallets matching filter, paginated by
+// keyset like ListTransactions.
+func (r *WalletRepository) ListWallets(ctx context.Context, filter models.WalletFilter) (*models.WalletPage, error) {
+	sortColumn := "created_at"
+	if filter.SortBy == models.WalletSortByBalance {
+		sortColumn = "balance"
+	}
+	direction, comparison := "DESC", "<"
+	if filter.Order == models.SortAsc {
+		direction, comparison = "ASC", ">"
+	}
+
+	var where whereClause
+	if filter.OwnerID != nil {
+		where.add("owner_id = $%d", *filter.OwnerID)
+	}
+	if len(filter.Statuses) > 0 {
+		values := make([]interface{}, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			values[i] = status
+		}
+		where.addIn("status", values)
+	}
+	if len(filter.Currencies) > 0 {
+		values := make([]interface{}, len(filter.Currencies))
+		for i, currency := range filter.Currencies {
+			values[i] = currency
+		}
+		where.addIn("currency", values)
+	}
+	if filter.MinBalance != nil {
+		where.add("balance >= $%d", *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		where.add("balance <= $%d", *filter.MaxBalance)
+	}
+	if filter.From != nil {
+		where.add("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		where.add("created_at < $%d", *filter.To)
+	}
+	if filter.Cursor != nil {
+		value, err := walletCursorValue(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		where.add("("+sortColumn+", id) "+comparison+" ($%d, $%d)", value, filter.Cursor.ID)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > models.MaxWalletPageSize {
+		limit = models.DefaultWalletPageSize
+	}
+	// Fetch one extra row to find out whether there is a next page
+	limitArg := where.limit(limit + 1)
+
+	query := fmt.Sprintf(`SELECT %s FROM wallets WHERE %s ORDER BY %s %s, id %s LIMIT $%d`,
+		walletColumns, where.String(), sortColumn, direction, direction, limitArg)
+
+	wallets := []models.Wallet{}
+	if err := r.db.SelectContext(ctx, &wallets, query, where.args...); err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+
+	page := &models.WalletPage{Wallets: wallets}
+	if len(wallets) > limit {
+		page.Wallets = wallets[:limit]
+		page.NextCursor = filter.CursorFor(page.Wallets[limit-1]).Encode()
+	}
+
+	return page, nil
+}
+
+func walletCursorValue(cursor *models.WalletCursor) (interface{}, error) {
+	if cursor.SortBy == models.WalletSortByBalance {
+		balance, err := models.ParseAmount(cursor.Value)
+		if err != nil {
+			return nil, models.ErrInvalidCursor
+		}
+		return balance, nil
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	return createdAt, nil
 }
 
-func (r *WalletRepository) Deposit(walletID uuid.UUID, amount float64) error {
-	tx, err := r.db.Beginx()
+// UpdateWalletBalance sets the balance directly. The difference is still
+// recorded in the ledger as an ADJUSTMENT entry. Frozen wallets can be
+// adjusted, closed ones cannot, and the balance cannot be lowered below what
+// active holds and the credit line allow.
+func (r *WalletRepository) UpdateWalletBalance(ctx context.Context, id uuid.UUID, newBalance models.Amount) error {
+	ctx, tx, done, err := beginTx(ctx, r.db, "update_balance")
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return err
 	}
-	defer tx.Rollback()
+	defer done()
 
-	// Lock the wallet row for update
-	var currentBalance float64
-	query := `SELECT balance FROM wallets WHERE id = $1 FOR UPDATE`
-	err = tx.Get(&currentBalance, query, walletID)
+	wallet, err := lockWallet(ctx, tx, id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("wallet not found")
-		}
-		return fmt.Errorf("failed to get wallet balance: %w", err)
+		return err
+	}
+	if wallet.Status == models.WalletClosed {
+		return apperrors.ErrWalletClosed
+	}
+	currentBalance := wallet.Balance
+	// Holds and the credit line bound an adjustment like any other debit
+	if delta := newBalance - currentBalance; delta < 0 && wallet.Spendable()+delta < 0 {
+		return apperrors.ErrInsufficientFunds
 	}
 
-	newBalance := currentBalance + amount
-	if newBalance < 0 {
-		return fmt.Errorf("insufficient funds")
+	if err := updateBalance(ctx, tx, id, newBalance); err != nil {
+		return err
 	}
 
-	updateQuery := `UPDATE wallets SET balance = $1, updated_at = NOW() WHERE id = $2`
-	_, err = tx.Exec(updateQuery, newBalance, walletID)
-	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
+	if diff := newBalance - currentBalance; diff != 0 {
+		if diff < 0 {
+			diff = -diff
+		}
+		entry := &models.Transaction{
+			ID:            uuid.New(),
+			WalletID:      id,
+			OperationType: models.ADJUSTMENT,
+			Amount:        diff,
+			Currency:      wallet.Currency,
+			BalanceBefore: currentBalance,
+			BalanceAfter:  newBalance,
+		}
+		if err := insertTransaction(ctx, tx, entry); err != nil {
+			return err
+		}
 	}
 
-	if err = tx.Commit(); err != nil {
+	if err = commit(ctx, tx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
-func (r *WalletRepository) Withdraw(walletID uuid.UUID, amount float64) error {
-	tx, err := r.db.Beginx()
+func (r *WalletRepository) Deposit(ctx context.Context, walletID uuid.UUID, amount models.Amount, currency models.Currency) (*models.Transaction, error) {
+	return r.applyOperation(ctx, walletID, models.DEPOSIT, amount, amount, currency, nil)
+}
+
+func (r *WalletRepository) Withdraw(ctx context.Context, walletID uuid.UUID, amount models.Amount, currency models.Currency, fee *models.Fee) (*models.Transaction, error) {
+	return r.applyOperation(ctx, walletID, models.WITHDRAW, amount, -amount, currency, fee)
+}
+
+// applyOperation changes the wallet balance by delta and appends the
+// corresponding ledger entry within a single database transaction. A non-nil
+// fee is charged to the wallet in the same transaction.
+func (r *WalletRepository) applyOperation(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount, delta models.Amount, currency models.Currency, fee *models.Fee) (*models.Transaction, error) {
+	ctx, tx, done, err := beginTx(ctx, r.db, strings.ToLower(string(operationType)))
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, err
 	}
-	defer tx.Rollback()
+	defer done()
+	trace.SpanFromContext(ctx).SetAttributes(
+		tracing.WalletIDKey.String(walletID.String()), tracing.OperationTypeKey.String(string(operationType)))
 
-	// Lock the wallet row for update
-	var currentBalance float64
-	query := `SELECT balance FROM wallets WHERE id = $1 FOR UPDATE`
-	err = tx.Get(&currentBalance, query, walletID)
+	wallets, err := lockWallets(ctx, tx, walletID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("wallet not found")
+		var missing *missingWalletError
+		if errors.As(err, &missing) && missing.walletID == walletID {
+			return nil, apperrors.ErrWalletNotFound
 		}
-		return fmt.Errorf("failed to get wallet balance: %w", err)
+		return nil, err
+	}
+
+	entry, err := r.bookOperation(ctx, tx, wallets, walletID, operationType, amount, delta, currency, fee)
+	if err != nil {
+		return nil, err
 	}
 
-	newBalance := currentBalance - amount
-	if newBalance < 0 {
-		return fmt.Errorf("insufficient funds")
+	if err = commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	updateQuery := `UPDATE wallets SET balance = $1, updated_at = NOW() WHERE id = $2`
-	_, err = tx.Exec(updateQuery, newBalance, walletID)
+	return entry, nil
+}
+
+// bookOperation is applyOperation on wallets already locked in tx. Their
+// state is kept up to date, so several operations can be booked in a row.
+func (r *WalletRepository) bookOperation(ctx context.Context, tx *sqlx.Tx, wallets map[uuid.UUID]*walletState, walletID uuid.UUID, operationType models.OperationType, amount, delta models.Amount, currency models.Currency, fee *models.Fee) (*models.Transaction, error) {
+	wallet := wallets[walletID]
+
+	var err error
+	if delta < 0 {
+		err = wallet.checkDebit()
+	} else {
+		err = wallet.checkCredit(r.FrozenDeposits)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
+		return nil, err
+	}
+	if wallet.Currency != currency {
+		return nil, apperrors.ErrCurrencyMismatch
 	}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	currentBalance := wallet.Balance
+	newBalance := currentBalance + delta
+	charge := feeAmount(fee)
+	if (delta < 0 || charge > 0) && wallet.Spendable()+delta-charge < 0 {
+		return nil, apperrors.ErrInsufficientFunds
+	}
+	if err := r.checkLimits(ctx, tx, walletID, wallet, operationType, amount, newBalance-charge); err != nil {
+		return nil, err
 	}
 
-	return nil
+	if err := updateBalance(ctx, tx, walletID, newBalance); err != nil {
+		return nil, err
+	}
+	wallet.Balance = newBalance
+
+	entry := &models.Transaction{
+		ID:            uuid.New(),
+		WalletID:      walletID,
+		OperationType: operationType,
+		Amount:        amount,
+		Currency:      currency,
+		BalanceBefore: currentBalance,
+		BalanceAfter:  newBalance,
+	}
+	if err := insertTransaction(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+	if fee != nil {
+		if err := chargeFee(ctx, tx, fee, walletID, wallets, entry.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return entry, nil
 }
 
-func (r *WalletRepository) PerformOperation(walletID uuid.UUID, operationType models.OperationType, amount float64) error {
+func (r *WalletRepository) PerformOperation(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount models.Amount, currency models.Currency, fee *models.Fee) (*models.Transaction, error) {
 	switch operationType {
 	case models.DEPOSIT:
-		return r.Deposit(walletID, amount)
+		return r.applyOperation(ctx, walletID, models.DEPOSIT, amount, amount, currency, fee)
 	case models.WITHDRAW:
-		return r.Withdraw(walletID, amount)
+		return r.Withdraw(ctx, walletID, amount, currency, fee)
 	default:
-		return fmt.Errorf("invalid operation type")
+		return nil, apperrors.ErrInvalidOperationType
 	}
 }
 
-func (r *WalletRepository) Transfer(fromWalletID, toWalletID uuid.UUID, amount float64) error {
+// Transfer moves amount from one wallet to another. A nil conversion means
+// both wallets hold currency; otherwise the destination is credited with the
+// converted amount and both legs record the conversion. A non-nil fee is
+// charged to the source wallet on top of amount.
+func (r *WalletRepository) Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount models.Amount, currency models.Currency, conversion *models.CurrencyConversion, fee *models.Fee) (*models.Transfer, error) {
 	if fromWalletID == toWalletID {
-		return fmt.Errorf("cannot transfer to the same wallet")
+		return nil, apperrors.ErrSameWallet
 	}
 
-	tx, err := r.db.Beginx()
+	ctx, tx, done, err := beginTx(ctx, r.db, "transfer")
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, err
 	}
-	defer tx.Rollback()
+	defer done()
 
-	// Lock the source wallet row for update
-	var fromBalance float64
-	query := `SELECT balance FROM wallets WHERE id = $1 FOR UPDATE`
-	err = tx.Get(&fromBalance, query, fromWalletID)
+	transfer, err := r.transferTx(ctx, tx, fromWalletID, toWalletID, amount, currency, conversion, fee)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("source wallet not found")
-		}
-		return fmt.Errorf("failed to get source wallet balance: %w", err)
+		return nil, err
 	}
 
-	if fromBalance < amount {
-		return fmt.Errorf("insufficient funds")
+	if err = commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// Lock the destination wallet row for update
-	var toBalance float64
-	err = tx.Get(&toBalance, query, toWalletID)
+	return transfer, nil
+}
+
+// transferTx books a transfer, and its fee if any, within tx.
+func (r *WalletRepository) transferTx(ctx context.Context, tx *sqlx.Tx, fromWalletID, toWalletID uuid.UUID, amount models.Amount, currency models.Currency, conversion *models.CurrencyConversion, fee *models.Fee) (*models.Transfer, error) {
+	// Lock the wallet rows in a fixed order so that concurrent transfers in
+	// opposite directions cannot deadlock
+	wallets, err := lockWallets(ctx, tx, fromWalletID, toWalletID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return fmt.Errorf("destination wallet not found")
+		var missing *missingWalletError
+		if errors.As(err, &missing) {
+			switch missing.walletID {
+			case fromWalletID:
+				return nil, apperrors.ErrSourceWalletNotFound
+			case toWalletID:
+				return nil, apperrors.ErrDestinationWalletNotFound
+			}
+		}
+		return nil, err
+	}
+
+	return r.bookTransfer(ctx, tx, wallets, fromWalletID, toWalletID, amount, currency, conversion, fee)
+}
+
+// bookTransfer is transferTx on wallets already locked in tx, see
+// bookOperation.
+func (r *WalletRepository) bookTransfer(ctx context.Context, tx *sqlx.Tx, wallets map[uuid.UUID]*walletState, fromWalletID, toWalletID uuid.UUID, amount models.Amount, currency models.Currency, conversion *models.CurrencyConversion, fee *models.Fee) (*models.Transfer, error) {
+	credit, creditCurrency := amount, currency
+	if conversion != nil {
+		credit, creditCurrency = conversion.DestinationAmount, conversion.DestinationCurrency
+	}
+
+	if err := wallets[fromWalletID].checkDebit(); err != nil {
+		return nil, err
+	}
+	if err := wallets[toWalletID].checkCredit(r.FrozenDeposits); err != nil {
+		return nil, err
+	}
+	if wallets[fromWalletID].Currency != currency || wallets[toWalletID].Currency != creditCurrency {
+		return nil, apperrors.ErrCurrencyMismatch
+	}
+
+	fromBalance, toBalance := wallets[fromWalletID].Balance, wallets[toWalletID].Balance
+	if wallets[fromWalletID].Spendable() < amount+feeAmount(fee) {
+		return nil, apperrors.ErrInsufficientFunds
+	}
+	if err := r.checkLimits(ctx, tx, fromWalletID, wallets[fromWalletID], models.TRANSFER_OUT, amount, fromBalance-amount-feeAmount(fee)); err != nil {
+		return nil, err
+	}
+	if err := r.checkLimits(ctx, tx, toWalletID, wallets[toWalletID], models.TRANSFER_IN, credit, toBalance+credit); err != nil {
+		return nil, err
+	}
+
+	if conversion != nil && conversion.QuoteID != nil {
+		if err := consumeQuote(ctx, tx, *conversion.QuoteID); err != nil {
+			return nil, err
 		}
-		return fmt.Errorf("failed to get destination wallet balance: %w", err)
 	}
 
 	// Update balances
 	newFromBalance := fromBalance - amount
-	newToBalance := toBalance + amount
+	newToBalance := toBalance + credit
 
-	updateQuery := `UPDATE wallets SET balance = $1, updated_at = NOW() WHERE id = $2`
-	_, err = tx.Exec(updateQuery, newFromBalance, fromWalletID)
-	if err != nil {
-		return fmt.Errorf("failed to update source wallet balance: %w", err)
+	if err := updateBalance(ctx, tx, fromWalletID, newFromBalance); err != nil {
+		return nil, err
+	}
+	if err := updateBalance(ctx, tx, toWalletID, newToBalance); err != nil {
+		return nil, err
+	}
+	wallets[fromWalletID].Balance, wallets[toWalletID].Balance = newFromBalance, newToBalance
+
+	// Both legs share the transfer ID as reference so the transfer can be reconstructed
+	transfer := &models.Transfer{
+		ID:                  uuid.New(),
+		FromWalletID:        fromWalletID,
+		ToWalletID:          toWalletID,
+		Amount:              amount,
+		Currency:            currency,
+		DestinationAmount:   credit,
+		DestinationCurrency: creditCurrency,
+	}
+	outgoing := &models.Transaction{
+		ID:                   uuid.New(),
+		WalletID:             fromWalletID,
+		OperationType:        models.TRANSFER_OUT,
+		Amount:               amount,
+		Currency:             currency,
+		BalanceBefore:        fromBalance,
+		BalanceAfter:         newFromBalance,
+		CounterpartyWalletID: &toWalletID,
+		ReferenceID:          &transfer.ID,
 	}
+	incoming := &models.Transaction{
+		ID:                   uuid.New(),
+		WalletID:             toWalletID,
+		OperationType:        models.TRANSFER_IN,
+		Amount:               credit,
+		Currency:             creditCurrency,
+		BalanceBefore:        toBalance,
+		BalanceAfter:         newToBalance,
+		CounterpartyWalletID: &fromWalletID,
+		ReferenceID:          &transfer.ID,
+	}
+	if conversion != nil {
+		transfer.ExchangeRate = &conversion.Rate
+		transfer.QuoteID = conversion.QuoteID
+
+		outgoing.ExchangeRate, incoming.ExchangeRate = &conversion.Rate, &conversion.Rate
+		outgoing.RoundingAdjustment, incoming.RoundingAdjustment = &conversion.RoundingAdjustment, &conversion.RoundingAdjustment
+		outgoing.CounterpartyAmount, outgoing.CounterpartyCurrency = &credit, &creditCurrency
+		incoming.CounterpartyAmount, incoming.CounterpartyCurrency = &amount, &currency
+	}
+
+	for _, leg := range []*models.Transaction{outgoing, incoming} {
+		if err := insertTransaction(ctx, tx, leg); err != nil {
+			return nil, err
+		}
+		transfer.Transactions = append(transfer.Transactions, *leg)
+	}
+	transfer.CreatedAt = outgoing.CreatedAt
+
+	if fee != nil {
+		if err := chargeFee(ctx, tx, fee, fromWalletID, wallets, transfer.ID); err != nil {
+			return nil, err
+		}
+		transfer.Fee = fee
+	}
+
+	return transfer, nil
+}
+
+// orderedWalletIDs returns the distinct IDs in the order their locks must be
+// taken.
+func orderedWalletIDs(ids ...uuid.UUID) []uuid.UUID {
+	ordered := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(ordered, id) {
+			ordered = append(ordered, id)
+		}
+	}
+	slices.SortFunc(ordered, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return ordered
+}
+
+// missingWalletError tells which of the wallets locked by lockWallets does
+// not exist. Callers translate it into the not found error of the role the
+// wallet plays.
+type missingWalletError struct {
+	walletID uuid.UUID
+}
+
+func (e *missingWalletError) Error() string {
+	return fmt.Sprintf("wallet %s not found", e.walletID)
+}
+
+// lockWallets locks the wallet rows in the order of orderedWalletIDs and
+// returns their state by ID.
+func lockWallets(ctx context.Context, tx *sqlx.Tx, ids ...uuid.UUID) (map[uuid.UUID]*walletState, error) {
+	wallets := make(map[uuid.UUID]*walletState, len(ids))
+	for _, id := range orderedWalletIDs(ids...) {
+		wallet, err := lockWallet(ctx, tx, id)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrWalletNotFound) {
+				return nil, &missingWalletError{walletID: id}
+			}
+			return nil, err
+		}
+		wallets[id] = wallet
+	}
+	return wallets, nil
+}
 
-	_, err = tx.Exec(updateQuery, newToBalance, toWalletID)
+// lockWallet locks the wallet row for update and returns its current state.
+// Its span covers the wait for the row lock.
+func lockWallet(ctx context.Context, tx *sqlx.Tx, walletID uuid.UUID) (_ *walletState, err error) {
+	ctx, span := startStatement(ctx, "db.lock_wallet", lockWalletQuery, tracing.WalletIDKey.String(walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	var wallet walletState
+	err = tx.GetContext(ctx, &wallet, lockWalletQuery, walletID)
 	if err != nil {
-		return fmt.Errorf("failed to update destination wallet balance: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.ErrWalletNotFound
+		}
+		return nil, fmt.Errorf("failed to get wallet balance: %w", err)
 	}
+	return &wallet, nil
+}
 
-	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+func updateBalance(ctx context.Context, tx *sqlx.Tx, walletID uuid.UUID, balance models.Amount) (err error) {
+	ctx, span := startStatement(ctx, "db.update_balance", updateBalanceQuery, tracing.WalletIDKey.String(walletID.String()))
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := tx.ExecContext(ctx, updateBalanceQuery, balance, walletID); err != nil {
+		return fmt.Errorf("failed to update balance: %w", err)
 	}
+	return nil
+}
+
+func insertTransaction(ctx context.Context, tx *sqlx.Tx, entry *models.Transaction) (err error) {
+	ctx, span := startStatement(ctx, "db.insert_transaction", insertTxQuery,
+		tracing.WalletIDKey.String(entry.WalletID.String()), tracing.OperationTypeKey.String(string(entry.OperationType)))
+	defer func() { tracing.End(span, err) }()
 
+	err = tx.GetContext(ctx, &entry.CreatedAt, insertTxQuery,
+		entry.ID, entry.WalletID, entry.OperationType, entry.Amount, entry.Currency,
+		entry.BalanceBefore, entry.BalanceAfter, entry.CounterpartyWalletID, entry.ReferenceID,
+		entry.ExchangeRate, entry.CounterpartyAmount, entry.CounterpartyCurrency, entry.RoundingAdjustment, entry.ReversedTransactionID)
+	if err != nil {
+		return fmt.Errorf("failed to record transaction: %w", err)
+	}
 	return nil
 }
@@ -1,12 +1,19 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/lock"
+	"wallet_service/internal/models"
+
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 func TestWalletRepository_GetWalletByID(t *testing.T) {
@@ -22,14 +29,14 @@ func TestWalletRepository_GetWalletByID(t *testing.T) {
 	walletID := uuid.New()
 	createdAt := time.Now()
 	updatedAt := time.Now()
-	rows := sqlmock.NewRows([]string{"id", "balance", "created_at", "updated_at"}).
-		AddRow(walletID, 100.0, createdAt, updatedAt)
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "status", "tier", "balance", "held_balance", "available_balance", "credit_limit", "currency", "created_at", "updated_at"}).
+		AddRow(walletID, "customer-1", "FROZEN", "standard", "100.00", "25.00", "75.00", "0.00", "USD", createdAt, updatedAt)
 
-	mock.ExpectQuery("SELECT id, balance, created_at, updated_at FROM wallets WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, owner_id, status, tier, balance, held_balance, balance - held_balance AS available_balance, credit_limit, currency, created_at, updated_at\\s+FROM wallets WHERE id = \\$1").
 		WithArgs(walletID).
 		WillReturnRows(rows)
 
-	wallet, err := repo.GetWalletByID(walletID)
+	wallet, err := repo.GetWalletByID(context.Background(), walletID)
 	if err != nil {
 		t.Fatalf("Failed to get wallet: %v", err)
 	}
@@ -38,10 +45,52 @@ func TestWalletRepository_GetWalletByID(t *testing.T) {
 		t.Errorf("Expected wallet ID %v, got %v", walletID, wallet.ID)
 	}
 
-	if wallet.Balance != 100.0 {
+	if wallet.Balance != models.NewAmount(100, 0) {
 		t.Errorf("Expected balance 100.0, got %v", wallet.Balance)
 	}
 
+	if wallet.AvailableBalance != models.NewAmount(75, 0) {
+		t.Errorf("Expected available balance 75.0, got %v", wallet.AvailableBalance)
+	}
+
+	if wallet.OwnerID == nil || *wallet.OwnerID != "customer-1" {
+		t.Errorf("Expected owner customer-1, got %v", wallet.OwnerID)
+	}
+
+	if wallet.Status != models.WalletFrozen {
+		t.Errorf("Expected status FROZEN, got %v", wallet.Status)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_GetWallets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID, missingID := uuid.New(), uuid.New()
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "status", "tier", "balance", "held_balance", "available_balance", "credit_limit", "currency", "created_at", "updated_at"}).
+		AddRow(walletID, nil, "ACTIVE", "standard", "10.00", "0.00", "10.00", "0.00", "EUR", time.Now(), time.Now())
+
+	mock.ExpectQuery("SELECT id, owner_id, .* FROM wallets WHERE id = ANY\\(\\$1::uuid\\[\\]\\)").
+		WithArgs(pq.StringArray{walletID.String(), missingID.String()}).
+		WillReturnRows(rows)
+
+	wallets, err := repo.GetWallets(context.Background(), []uuid.UUID{walletID, missingID})
+	if err != nil {
+		t.Fatalf("Failed to get wallets: %v", err)
+	}
+	if len(wallets) != 1 || wallets[walletID] == nil || wallets[walletID].Currency != "EUR" {
+		t.Errorf("Expected only wallet %v, got %+v", walletID, wallets)
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
@@ -58,13 +107,19 @@ func TestWalletRepository_UpdateWalletBalance(t *testing.T) {
 	repo := NewWalletRepository(sqlxDB)
 
 	walletID := uuid.New()
-	newBalance := 200.0
+	newBalance := models.NewAmount(200, 0)
 
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "150.00"})
 	mock.ExpectExec("UPDATE wallets SET balance = \\$1, updated_at = NOW\\(\\) WHERE id = \\$2").
-		WithArgs(newBalance, walletID).
+		WithArgs("200.00", walletID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), walletID, models.ADJUSTMENT, "50.00", "USD", "150.00", "200.00", nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
 
-	err = repo.UpdateWalletBalance(walletID, newBalance)
+	err = repo.UpdateWalletBalance(context.Background(), walletID, newBalance)
 	if err != nil {
 		t.Fatalf("Failed to update wallet balance: %v", err)
 	}
@@ -73,3 +128,432 @@ func TestWalletRepository_UpdateWalletBalance(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestWalletRepository_UpdateWalletBalance_InsufficientFunds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID := uuid.New()
+
+	// 100.00 with 30.00 held and a 20.00 credit line goes down to 10.00 at most
+	tests := []struct {
+		balance  int64
+		expected error
+	}{
+		{10, nil},
+		{9, apperrors.ErrInsufficientFunds},
+	}
+
+	for _, tt := range tests {
+		balance, expected := tt.balance, tt.expected
+		mock.ExpectBegin()
+		expectLockWallet(mock, walletID, lockedWallet{Balance: "100.00", Held: "30.00", CreditLimit: "20.00"})
+		if expected == nil {
+			mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+				WithArgs("10.00", walletID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery("INSERT INTO transactions").
+				WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+			mock.ExpectCommit()
+		} else {
+			mock.ExpectRollback()
+		}
+
+		err = repo.UpdateWalletBalance(context.Background(), walletID, models.NewAmount(balance, 0))
+		if !errors.Is(err, expected) {
+			t.Errorf("Adjusting to %d: expected %v, got %v", balance, expected, err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+	}
+}
+
+func TestWalletRepository_Deposit_RecordsTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewWalletRepository(sqlxDB)
+
+	walletID := uuid.New()
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "10.10"})
+	mock.ExpectExec("UPDATE wallets SET balance").
+		WithArgs("10.30", walletID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), walletID, models.DEPOSIT, "0.20", "USD", "10.10", "10.30", nil, nil, nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	transaction, err := repo.Deposit(context.Background(), walletID, models.NewAmount(0, 20), "USD")
+	if err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+
+	if transaction.ID == uuid.Nil {
+		t.Error("Expected transaction ID to be set")
+	}
+
+	if transaction.BalanceAfter != models.NewAmount(10, 30) {
+		t.Errorf("Expected balance after 10.30, got %v", transaction.BalanceAfter)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Deposit_TakesLockerLocksInTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID := uuid.New()
+	ctx, unlock, err := lock.NewPostgresLocker().Lock(context.Background(), walletID)
+	if err != nil {
+		t.Fatalf("Failed to lock: %v", err)
+	}
+	defer unlock()
+
+	// The advisory lock is taken on the connection of the operation itself
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock\\(\\$1\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "10.00"})
+	mock.ExpectExec("UPDATE wallets SET balance").
+		WithArgs("11.00", walletID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	if _, err := repo.Deposit(ctx, walletID, models.NewAmount(1, 0), "USD"); err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+func TestWalletRepository_Withdraw_InsufficientFunds(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewWalletRepository(sqlxDB)
+
+	walletID := uuid.New()
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "5.00"})
+	mock.ExpectRollback()
+
+	_, err = repo.Withdraw(context.Background(), walletID, models.NewAmount(5, 1), "USD", nil)
+	if !errors.Is(err, apperrors.ErrInsufficientFunds) {
+		t.Fatalf("Expected insufficient funds error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_ListTransactions_Pagination(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewWalletRepository(sqlxDB)
+
+	walletID := uuid.New()
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	columns := []string{"id", "wallet_id", "operation_type", "amount", "currency", "balance_before", "balance_after", "counterparty_wallet_id", "reference_id",
+		"exchange_rate", "counterparty_amount", "counterparty_currency", "rounding_adjustment", "reversed_transaction_id", "created_at"}
+	rows := sqlmock.NewRows(columns)
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, id := range ids {
+		rows.AddRow(id, walletID, "DEPOSIT", "1.00", "USD", "0.00", "1.00", nil, nil, nil, nil, nil, nil, nil, createdAt.Add(-time.Duration(i)*time.Minute))
+	}
+
+	minAmount := models.NewAmount(1, 0)
+	filter := models.TransactionFilter{
+		OperationTypes: []models.OperationType{models.DEPOSIT},
+		MinAmount:      &minAmount,
+		SortBy:         models.SortByCreatedAt,
+		Order:          models.SortDesc,
+		Limit:          2,
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM transactions WHERE wallet_id = \\$1 AND operation_type IN \\(\\$2\\) AND amount >= \\$3 ORDER BY created_at DESC, id DESC LIMIT \\$4").
+		WithArgs(walletID, models.DEPOSIT, "1.00", 3).
+		WillReturnRows(rows)
+
+	page, err := repo.ListTransactions(context.Background(), walletID, filter)
+	if err != nil {
+		t.Fatalf("Failed to list transactions: %v", err)
+	}
+
+	if len(page.Transactions) != 2 {
+		t.Fatalf("Expected 2 transactions, got %d", len(page.Transactions))
+	}
+
+	cursor, err := models.DecodeCursor[models.TransactionSortField](page.NextCursor)
+	if err != nil {
+		t.Fatalf("Failed to decode next cursor: %v", err)
+	}
+
+	if cursor.ID != ids[1] {
+		t.Errorf("Expected cursor to point at %v, got %v", ids[1], cursor.ID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_ListWallets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	owner := "customer-1"
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "status", "tier", "balance", "held_balance", "available_balance", "credit_limit", "currency", "created_at", "updated_at"})
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+	for i, id := range ids {
+		rows.AddRow(id, owner, "ACTIVE", "standard", "10.00", "0.00", "10.00", "0.00", "EUR", createdAt.Add(time.Duration(i)*time.Minute), createdAt)
+	}
+
+	minBalance := models.NewAmount(5, 0)
+	cursor := models.WalletCursor{SortBy: models.WalletSortByBalance, Order: models.SortAsc, Value: "5.00", ID: uuid.New()}
+	filter := models.WalletFilter{
+		OwnerID:    &owner,
+		Statuses:   []models.WalletStatus{models.WalletActive},
+		Currencies: []models.Currency{"EUR", "USD"},
+		MinBalance: &minBalance,
+		SortBy:     models.WalletSortByBalance,
+		Order:      models.SortAsc,
+		Limit:      1,
+		Cursor:     &cursor,
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM wallets WHERE owner_id = \\$1 AND status IN \\(\\$2\\) AND currency IN \\(\\$3, \\$4\\) "+
+		"AND balance >= \\$5 AND \\(balance, id\\) > \\(\\$6, \\$7\\) ORDER BY balance ASC, id ASC LIMIT \\$8").
+		WithArgs(owner, "ACTIVE", "EUR", "USD", "5.00", "5.00", cursor.ID, 2).
+		WillReturnRows(rows)
+
+	page, err := repo.ListWallets(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("Failed to list wallets: %v", err)
+	}
+
+	if len(page.Wallets) != 1 || page.Wallets[0].ID != ids[0] {
+		t.Fatalf("Expected the first wallet only, got %+v", page.Wallets)
+	}
+
+	next, err := models.DecodeCursor[models.WalletSortField](page.NextCursor)
+	if err != nil {
+		t.Fatalf("Failed to decode next cursor: %v", err)
+	}
+	if next.ID != ids[0] || next.Value != "10.00" {
+		t.Errorf("Unexpected next cursor %+v", next)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Transfer_LocksInOrderAndRecordsLegs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewWalletRepository(sqlxDB)
+
+	fromWalletID := uuid.MustParse("ffffffff-0000-0000-0000-000000000000")
+	toWalletID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, toWalletID, lockedWallet{Balance: "1.00"})
+	expectLockWallet(mock, fromWalletID, lockedWallet{Balance: "10.00"})
+	mock.ExpectExec("UPDATE wallets SET balance").
+		WithArgs("7.50", fromWalletID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE wallets SET balance").
+		WithArgs("3.50", toWalletID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), fromWalletID, models.TRANSFER_OUT, "2.50", "USD", "10.00", "7.50", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), toWalletID, models.TRANSFER_IN, "2.50", "USD", "1.00", "3.50", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, nil, nil, nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	transfer, err := repo.Transfer(context.Background(), fromWalletID, toWalletID, models.NewAmount(2, 50), "USD", nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to transfer: %v", err)
+	}
+
+	if len(transfer.Transactions) != 2 {
+		t.Fatalf("Expected 2 ledger legs, got %d", len(transfer.Transactions))
+	}
+
+	for _, leg := range transfer.Transactions {
+		if leg.ReferenceID == nil || *leg.ReferenceID != transfer.ID {
+			t.Errorf("Expected leg %v to reference transfer %v", leg.ID, transfer.ID)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Deposit_CurrencyMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewWalletRepository(sqlxDB)
+
+	walletID := uuid.New()
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "5.00", Currency: "EUR"})
+	mock.ExpectRollback()
+
+	_, err = repo.Deposit(context.Background(), walletID, models.NewAmount(1, 0), "USD")
+	if !errors.Is(err, apperrors.ErrCurrencyMismatch) {
+		t.Fatalf("Expected currency mismatch error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Transfer_CrossCurrencyWithQuote(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewWalletRepository(sqlxDB)
+
+	fromWalletID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	toWalletID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	quoteID := uuid.New()
+	conversion := &models.CurrencyConversion{
+		DestinationAmount:   models.NewAmount(9, 0),
+		DestinationCurrency: "EUR",
+		Rate:                "0.9",
+		RoundingAdjustment:  "0",
+		QuoteID:             &quoteID,
+	}
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, fromWalletID, lockedWallet{Balance: "10.00"})
+	expectLockWallet(mock, toWalletID, lockedWallet{Balance: "0.00", Currency: "EUR"})
+	mock.ExpectExec("UPDATE fx_quotes SET used_at = NOW\\(\\) WHERE id = \\$1 AND used_at IS NULL AND expires_at > NOW\\(\\)").
+		WithArgs(quoteID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE wallets SET balance").
+		WithArgs("0.00", fromWalletID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE wallets SET balance").
+		WithArgs("9.00", toWalletID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), fromWalletID, models.TRANSFER_OUT, "10.00", "USD", "10.00", "0.00", toWalletID, sqlmock.AnyArg(), "0.9", "9.00", "EUR", "0", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectQuery("INSERT INTO transactions").
+		WithArgs(sqlmock.AnyArg(), toWalletID, models.TRANSFER_IN, "9.00", "EUR", "0.00", "9.00", fromWalletID, sqlmock.AnyArg(), "0.9", "10.00", "USD", "0", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	transfer, err := repo.Transfer(context.Background(), fromWalletID, toWalletID, models.NewAmount(10, 0), "USD", conversion, nil)
+	if err != nil {
+		t.Fatalf("Failed to transfer: %v", err)
+	}
+
+	if transfer.DestinationAmount != models.NewAmount(9, 0) || transfer.DestinationCurrency != "EUR" {
+		t.Errorf("Expected 9.00 EUR credited, got %v %v", transfer.DestinationAmount, transfer.DestinationCurrency)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Transfer_QuoteAlreadyUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "sqlmock")
+	repo := NewWalletRepository(sqlxDB)
+
+	fromWalletID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	toWalletID := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	quoteID := uuid.New()
+	conversion := &models.CurrencyConversion{DestinationAmount: 900, DestinationCurrency: "EUR", Rate: "0.9", RoundingAdjustment: "0", QuoteID: &quoteID}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT balance, held_balance, credit_limit, currency, status, tier, limits FROM wallets").
+		WithArgs(fromWalletID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "held_balance", "credit_limit", "currency", "status", "tier", "limits"}).AddRow("10.00", "0.00", "0.00", "USD", "ACTIVE", "standard", "{}"))
+	mock.ExpectQuery("SELECT balance, held_balance, credit_limit, currency, status, tier, limits FROM wallets").
+		WithArgs(toWalletID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "held_balance", "credit_limit", "currency", "status", "tier", "limits"}).AddRow("0.00", "0.00", "0.00", "EUR", "ACTIVE", "standard", "{}"))
+	mock.ExpectExec("UPDATE fx_quotes SET used_at").
+		WithArgs(quoteID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, err = repo.Transfer(context.Background(), fromWalletID, toWalletID, models.NewAmount(10, 0), "USD", conversion, nil)
+	if !errors.Is(err, apperrors.ErrQuoteExpired) {
+		t.Fatalf("Expected quote expired error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const walletStatusChangeColumns = `id, wallet_id, from_status, to_status, reason, changed_by, created_at`
+
+// ChangeWalletStatus moves the wallet to status and appends the change to the
+// audit trail in the same transaction. A wallet is closed only once its
+// balance is zero and it has no active holds. Unless admin is set, only an
+// active wallet can be closed, so a frozen one stays frozen until an admin
+// acts on it.
+func (r *WalletRepository) ChangeWalletStatus(ctx context.Context, walletID uuid.UUID, status models.WalletStatus, reason, changedBy string, admin bool) (*models.WalletStatusChange, error) {
+	ctx, tx, done, err := beginTx(ctx, r.db, "change_status")
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	wallet, err := lockWallet(ctx, tx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Status == models.WalletClosed {
+		return nil, apperrors.ErrWalletClosed
+	}
+	if !wallet.Status.CanTransitionTo(status) {
+		return nil, apperrors.ErrInvalidStatusTransition
+	}
+	if !admin && wallet.Status == models.WalletFrozen {
+		return nil, apperrors.ErrWalletFrozen
+	}
+	if status == models.WalletClosed && (wallet.Balance != 0 || wallet.HeldBalance != 0) {
+		return nil, apperrors.ErrWalletNotEmpty
+	}
+
+	query := `UPDATE wallets SET status = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, status, walletID); err != nil {
+		return nil, fmt.Errorf("failed to update wallet status: %w", err)
+	}
+
+	change := &models.WalletStatusChange{
+		ID:         uuid.New(),
+		WalletID:   walletID,
+		FromStatus: wallet.Status,
+		ToStatus:   status,
+		Reason:     reason,
+		ChangedBy:  changedBy,
+	}
+	query = `INSERT INTO wallet_status_changes (id, wallet_id, from_status, to_status, reason, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at`
+	err = tx.GetContext(ctx, &change.CreatedAt, query,
+		change.ID, change.WalletID, change.FromStatus, change.ToStatus, change.Reason, change.ChangedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record status change: %w", err)
+	}
+
+	if err = commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return change, nil
+}
+
+// ListWalletStatusChanges returns the audit trail of the wallet, oldest first.
+func (r *WalletRepository) ListWalletStatusChanges(ctx context.Context, walletID uuid.UUID) ([]models.WalletStatusChange, error) {
+	changes := []models.WalletStatusChange{}
+	query := `SELECT ` + walletStatusChangeColumns + ` FROM wallet_status_changes WHERE wallet_id = $1 ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &changes, query, walletID); err != nil {
+		return nil, fmt.Errorf("failed to list status changes: %w", err)
+	}
+	return changes, nil
+}
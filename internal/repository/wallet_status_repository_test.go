@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const lockWalletPattern = "SELECT balance, held_balance, credit_limit, currency, status, tier, limits FROM wallets WHERE id = \\$1 FOR UPDATE"
+
+// lockedWallet is the row returned for a wallet locked by the repository.
+// Empty fields default to an active USD wallet of the standard tier without
+// holds, credit line or limits of its own.
+type lockedWallet struct {
+	Balance     string
+	Held        string
+	CreditLimit string
+	Currency    string
+	Status      models.WalletStatus
+	Tier        string
+	Limits      string
+}
+
+func expectLockWallet(mock sqlmock.Sqlmock, walletID uuid.UUID, wallet lockedWallet) {
+	or := func(value, fallback string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	}
+	mock.ExpectQuery(lockWalletPattern).
+		WithArgs(walletID).
+		WillReturnRows(sqlmock.NewRows([]string{"balance", "held_balance", "credit_limit", "currency", "status", "tier", "limits"}).
+			AddRow(wallet.Balance, or(wallet.Held, "0.00"), or(wallet.CreditLimit, "0.00"), or(wallet.Currency, "USD"),
+				or(string(wallet.Status), string(models.WalletActive)), or(wallet.Tier, models.DefaultWalletTier), or(wallet.Limits, "{}")))
+}
+
+func TestWalletRepository_Withdraw_FrozenWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	repo.FrozenDeposits = true
+
+	walletID := uuid.New()
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "10.00", Status: models.WalletFrozen})
+	mock.ExpectRollback()
+
+	_, err = repo.Withdraw(context.Background(), walletID, models.NewAmount(1, 0), "USD", nil)
+	if !errors.Is(err, apperrors.ErrWalletFrozen) {
+		t.Fatalf("Expected wallet frozen error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_Deposit_FrozenWallet(t *testing.T) {
+	for _, frozenDeposits := range []bool{false, true} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Failed to create mock database: %v", err)
+		}
+
+		repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+		repo.FrozenDeposits = frozenDeposits
+
+		walletID := uuid.New()
+
+		mock.ExpectBegin()
+		expectLockWallet(mock, walletID, lockedWallet{Balance: "10.00", Status: models.WalletFrozen})
+		if frozenDeposits {
+			mock.ExpectExec("UPDATE wallets SET balance = \\$1").
+				WithArgs("15.00", walletID).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery("INSERT INTO transactions").
+				WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+			mock.ExpectCommit()
+		} else {
+			mock.ExpectRollback()
+		}
+
+		_, err = repo.Deposit(context.Background(), walletID, models.NewAmount(5, 0), "USD")
+		if frozenDeposits && err != nil {
+			t.Errorf("Expected deposit to a frozen wallet to succeed, got %v", err)
+		}
+		if !frozenDeposits && !errors.Is(err, apperrors.ErrWalletFrozen) {
+			t.Errorf("Expected wallet frozen error, got %v", err)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unfulfilled expectations: %v", err)
+		}
+		db.Close()
+	}
+}
+
+func TestWalletRepository_Transfer_ClosedDestination(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+	repo.FrozenDeposits = true
+
+	fromID, toID := uuid.New(), uuid.New()
+	status := map[uuid.UUID]models.WalletStatus{fromID: models.WalletActive, toID: models.WalletClosed}
+
+	mock.ExpectBegin()
+	for _, id := range orderedWalletIDs(fromID, toID) {
+		expectLockWallet(mock, id, lockedWallet{Balance: "10.00", Status: status[id]})
+	}
+	mock.ExpectRollback()
+
+	_, err = repo.Transfer(context.Background(), fromID, toID, models.NewAmount(1, 0), "USD", nil, nil)
+	if !errors.Is(err, apperrors.ErrWalletClosed) {
+		t.Fatalf("Expected wallet closed error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestWalletRepository_ChangeWalletStatus_CloseRequiresEmptyWallet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewWalletRepository(sqlx.NewDb(db, "sqlmock"))
+
+	walletID := uuid.New()
+
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "0.00", Status: models.WalletFrozen})
+	mock.ExpectExec("UPDATE wallets SET status = \\$1").
+		WithArgs("CLOSED", walletID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO wallet_status_changes").
+		WithArgs(sqlmock.AnyArg(), walletID, "FROZEN", "CLOSED", "customer request", "admin").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	change, err := repo.ChangeWalletStatus(context.Background(), walletID, models.WalletClosed, "customer request", "admin", true)
+	if err != nil {
+		t.Fatalf("Failed to close wallet: %v", err)
+	}
+	if change.FromStatus != models.WalletFrozen || change.ToStatus != models.WalletClosed {
+		t.Errorf("Expected FROZEN -> CLOSED, got %v -> %v", change.FromStatus, change.ToStatus)
+	}
+
+	// Only admins close a frozen wallet
+	mock.ExpectBegin()
+	expectLockWallet(mock, walletID, lockedWallet{Balance: "0.00", Status: models.WalletFrozen})
+	mock.ExpectRollback()
+
+	_, err = repo.ChangeWalletStatus(context.Background(), walletID, models.WalletClosed, "customer request", "user:customer-1", false)
+	if !errors.Is(err, apperrors.ErrWalletFrozen) {
+		t.Errorf("Expected wallet frozen error, got %v", err)
+	}
+
+	// A wallet with money left, or with money reserved by holds, stays open
+	for _, balances := range [][2]string{{"0.01", "0.00"}, {"5.00", "5.00"}} {
+		mock.ExpectBegin()
+		expectLockWallet(mock, walletID, lockedWallet{Balance: balances[0], Held: balances[1]})
+		mock.ExpectRollback()
+
+		_, err = repo.ChangeWalletStatus(context.Background(), walletID, models.WalletClosed, "customer request", "admin", true)
+		if !errors.Is(err, apperrors.ErrWalletNotEmpty) {
+			t.Errorf("Expected wallet not empty error, got %v", err)
+		}
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"wallet_service/handler"
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator resolves the credential sent by a client to its principal.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*auth.Principal, error)
+}
+
+// Authenticate rejects requests without valid credentials with 401 and puts
+// the principal of the others into the request context. Clients send either
+// an API key or a bearer token; tokens may be nil when bearer authentication
+// is not configured.
+func Authenticate(apiKeys, tokens Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authenticator, credential := apiKeys, c.GetHeader(APIKeyHeader)
+		if token, ok := bearerToken(c.GetHeader("Authorization")); ok {
+			authenticator, credential = tokens, token
+		}
+		if credential == "" || authenticator == nil {
+			handler.RespondError(c, apperrors.ErrUnauthorized)
+			return
+		}
+
+		ctx := c.Request.Context()
+		principal, err := authenticator.Authenticate(ctx, credential)
+		if err != nil {
+			handler.RespondError(c, err)
+			return
+		}
+
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("auth.principal_id", principal.ID))
+		c.Request = c.Request.WithContext(auth.WithPrincipal(ctx, principal))
+		c.Next()
+	}
+}
+
+// bearerToken returns the token of an "Authorization: Bearer <token>" header.
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// RequireScope rejects requests whose principal lacks scope with 403. It must
+// run after Authenticate.
+func RequireScope(scope auth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.PrincipalFrom(c.Request.Context())
+		if principal == nil {
+			handler.RespondError(c, apperrors.ErrUnauthorized)
+			return
+		}
+		if !principal.HasScope(scope) {
+			handler.RespondError(c, apperrors.ErrForbidden)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stubAuthenticator map[string]*auth.Principal
+
+func (s stubAuthenticator) Authenticate(ctx context.Context, credential string) (*auth.Principal, error) {
+	if principal, ok := s[credential]; ok {
+		return principal, nil
+	}
+	return nil, apperrors.ErrUnauthorized
+}
+
+func TestAuthenticate_EnforcesScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := r.Group("/api", Authenticate(stubAuthenticator{
+		"reader": {ID: "1", Scopes: []auth.Scope{auth.ScopeWalletsRead}},
+		"writer": {ID: "2", Scopes: []auth.Scope{auth.ScopeWalletsRead, auth.ScopeWalletsWrite}},
+	}, stubAuthenticator{
+		"token": {ID: "user:1", Scopes: []auth.Scope{auth.ScopeWalletsWrite}},
+	}))
+	ok := func(c *gin.Context) {
+		if auth.PrincipalFrom(c.Request.Context()) == nil {
+			t.Error("Expected principal in request context")
+		}
+		c.Status(http.StatusOK)
+	}
+	api.GET("/wallets", RequireScope(auth.ScopeWalletsRead), ok)
+	api.POST("/wallets", RequireScope(auth.ScopeWalletsWrite), ok)
+
+	tests := []struct {
+		method, key, authorization string
+		want                       int
+	}{
+		{http.MethodGet, "", "", http.StatusUnauthorized},
+		{http.MethodGet, "unknown", "", http.StatusUnauthorized},
+		{http.MethodGet, "reader", "", http.StatusOK},
+		{http.MethodPost, "reader", "", http.StatusForbidden},
+		{http.MethodPost, "writer", "", http.StatusOK},
+		{http.MethodPost, "", "Bearer token", http.StatusOK},
+		{http.MethodGet, "", "Bearer token", http.StatusForbidden},
+		{http.MethodPost, "", "Bearer reader", http.StatusUnauthorized},
+		{http.MethodPost, "", "Basic token", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/api/wallets", nil)
+		if tt.key != "" {
+			req.Header.Set(APIKeyHeader, tt.key)
+		}
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s with key %q and authorization %q: expected %d, got %d", tt.method, tt.key, tt.authorization, tt.want, w.Code)
+		}
+	}
+}
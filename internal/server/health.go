@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pressly/goose/v3"
+)
+
+const (
+	migrationsDir = "./migrations"
+
+	// healthCheckTimeout bounds the database calls made by a probe
+	healthCheckTimeout = 2 * time.Second
+)
+
+// Version is the build version reported by /status. It is set at build time
+// with -ldflags "-X wallet_service/internal/server.Version=...".
+var Version = "dev"
+
+// Healthz reports that the process is alive. It deliberately checks nothing
+// else, so a database outage does not get the instance restarted.
+func (s *Server) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz reports whether the instance should receive traffic: the database
+// answers, its schema is at the version this build expects and the
+// connection pool has room left. It turns not-ready as soon as shutdown
+// starts, before requests are drained.
+func (s *Server) Readyz(c *gin.Context) {
+	if !s.ready.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	checks := gin.H{"database": "ok", "migrations": "ok", "pool": "ok"}
+	ready := true
+	fail := func(check, reason string) {
+		checks[check] = reason
+		ready = false
+	}
+
+	if err := s.DB.PingContext(ctx); err != nil {
+		fail("database", err.Error())
+	}
+
+	if version, err := goose.GetDBVersionContext(ctx, s.DB.DB); err != nil {
+		fail("migrations", err.Error())
+	} else if version != s.expectedMigration {
+		fail("migrations", "database is at a different migration version than expected")
+	}
+
+	if stats := s.DB.Stats(); stats.MaxOpenConnections > 0 && stats.InUse >= stats.MaxOpenConnections {
+		fail("pool", "all connections are in use")
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
+
+// Status describes the running instance for humans and dashboards.
+func (s *Server) Status(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	var migrationVersion *int64
+	if version, err := goose.GetDBVersionContext(ctx, s.DB.DB); err == nil {
+		migrationVersion = &version
+	}
+
+	stats := s.DB.Stats()
+	c.JSON(http.StatusOK, gin.H{
+		"version":                    Version,
+		"started_at":                 s.startedAt,
+		"uptime_seconds":             int64(time.Since(s.startedAt).Seconds()),
+		"ready":                      s.ready.Load(),
+		"migration_version":          migrationVersion,
+		"expected_migration_version": s.expectedMigration,
+		"db_pool": gin.H{
+			"max_open":   stats.MaxOpenConnections,
+			"open":       stats.OpenConnections,
+			"in_use":     stats.InUse,
+			"idle":       stats.Idle,
+			"wait_count": stats.WaitCount,
+		},
+	})
+}
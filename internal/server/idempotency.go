@@ -0,0 +1,164 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"wallet_service/handler"
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	// idempotencyStoreTimeout bounds storing the outcome, which must happen
+	// even when the request context has already expired
+	idempotencyStoreTimeout = 5 * time.Second
+	// minIdempotencyLease is the lease of reservations when requests have no
+	// timeout, or a shorter one
+	minIdempotencyLease = time.Minute
+	// idempotencyCleanupBatchSize bounds how many keys one statement deletes
+	idempotencyCleanupBatchSize = 1000
+)
+
+// responseRecorder copies everything written to the client so the response
+// can be stored and replayed later.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST handlers safe to retry. A request carrying an
+// Idempotency-Key header is executed at most once per key: repeating it with
+// the same body replays the stored response, while reusing the key with a
+// different body is rejected with 422. Requests without the header are passed
+// through unchanged.
+//
+// A key is reserved for as long as a request may take, requestTimeout plus
+// the time to store its outcome. If the instance crashes meanwhile, retries
+// get 409 until the reservation runs out and are then executed again.
+func Idempotency(repo repository.IdempotencyRepositoryInterface, requestTimeout time.Duration) gin.HandlerFunc {
+	lease := max(requestTimeout, minIdempotencyLease) + idempotencyStoreTimeout
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			handler.RespondError(c, apperrors.New(apperrors.CodeInvalidRequest, "idempotency key is too long"))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			handler.RespondError(c, apperrors.New(apperrors.CodeInvalidRequest, "invalid request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// Keys are scoped to the client so that one client can neither replay
+		// nor block the responses of another
+		scope := c.Request.Method + " " + c.Request.URL.Path
+		if principal := auth.PrincipalFrom(c.Request.Context()); principal != nil {
+			scope = principal.ID + " " + scope
+		}
+		hash := sha256.Sum256(append([]byte(scope+"\n"), body...))
+		fingerprint := hex.EncodeToString(hash[:])
+
+		record, created, err := repo.Reserve(c.Request.Context(), scope, key, fingerprint, lease)
+		if err != nil {
+			handler.RespondError(c, err)
+			return
+		}
+
+		if !created {
+			switch {
+			case record.RequestHash != fingerprint:
+				handler.RespondError(c, apperrors.ErrIdempotencyKeyReused)
+			case !record.Completed():
+				handler.RespondError(c, apperrors.ErrIdempotencyKeyInProgress)
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(*record.ResponseStatus, "application/json; charset=utf-8", record.ResponseBody)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), idempotencyStoreTimeout)
+		defer cancel()
+
+		// Server errors are not cached so that the client can retry with the
+		// same key, and neither are requests that failed because the client
+		// went away or they ran out of time. A crash before Complete leaves the
+		// key reserved until its lease runs out.
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError || status == handler.StatusClientClosedRequest ||
+			(status >= http.StatusBadRequest && c.Request.Context().Err() != nil) {
+			if err := repo.Release(ctx, scope, key); err != nil {
+				slog.ErrorContext(ctx, "Failed to release idempotency key", "error", err)
+			}
+			return
+		}
+		if err := repo.Complete(ctx, scope, key, status, recorder.body.Bytes()); err != nil {
+			slog.ErrorContext(ctx, "Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// RunIdempotencyCleanup deletes, every interval until ctx is done, the keys
+// reserved longer than retention ago. Their requests can no longer be
+// replayed; a retry executes them again.
+func RunIdempotencyCleanup(ctx context.Context, repo repository.IdempotencyRepositoryInterface, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			total := 0
+			for {
+				deleted, err := repo.DeleteExpired(ctx, time.Now().Add(-retention), idempotencyCleanupBatchSize)
+				total += deleted
+				if err != nil {
+					slog.ErrorContext(ctx, "Failed to delete expired idempotency keys", "error", err)
+				}
+				if err != nil || deleted < idempotencyCleanupBatchSize {
+					break
+				}
+			}
+			if total > 0 {
+				slog.InfoContext(ctx, "Deleted expired idempotency keys", "count", total)
+			}
+		}
+	}
+}
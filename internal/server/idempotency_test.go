@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"wallet_service/handler"
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryIdempotencyRepo keeps idempotency keys in a map.
+type memoryIdempotencyRepo struct {
+	records map[string]*models.IdempotencyRecord
+}
+
+func newMemoryIdempotencyRepo() *memoryIdempotencyRepo {
+	return &memoryIdempotencyRepo{records: map[string]*models.IdempotencyRecord{}}
+}
+
+func (r *memoryIdempotencyRepo) Reserve(ctx context.Context, scope, key, requestHash string, lease time.Duration) (*models.IdempotencyRecord, bool, error) {
+	if record, ok := r.records[scope+" "+key]; ok {
+		return record, false, nil
+	}
+	record := &models.IdempotencyRecord{Scope: scope, Key: key, RequestHash: requestHash, CreatedAt: time.Now()}
+	r.records[scope+" "+key] = record
+	return record, true, nil
+}
+
+func (r *memoryIdempotencyRepo) Complete(ctx context.Context, scope, key string, status int, body []byte) error {
+	record := r.records[scope+" "+key]
+	record.ResponseStatus, record.ResponseBody = &status, body
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) Release(ctx context.Context, scope, key string) error {
+	delete(r.records, scope+" "+key)
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) DeleteExpired(ctx context.Context, before time.Time, limit int) (int, error) {
+	return 0, nil
+}
+
+// idempotentRouter serves POST /wallet with respond behind the Idempotency
+// middleware and counts how often respond runs.
+func idempotentRouter(repo *memoryIdempotencyRepo, calls *int, respond func(c *gin.Context)) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/wallet", Idempotency(repo, time.Second), func(c *gin.Context) {
+		*calls++
+		respond(c)
+	})
+	return r
+}
+
+func postIdempotent(r *gin.Engine, ctx context.Context, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/wallet", strings.NewReader(body)).WithContext(ctx)
+	req.Header.Set(IdempotencyKeyHeader, key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysResponse(t *testing.T) {
+	calls := 0
+	r := idempotentRouter(newMemoryIdempotencyRepo(), &calls, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	first := postIdempotent(r, context.Background(), "key-1", `{"amount": 10}`)
+	second := postIdempotent(r, context.Background(), "key-1", `{"amount": 10}`)
+
+	if calls != 1 {
+		t.Errorf("Expected the handler to run once, ran %d times", calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("Expected the first response replayed, got %d %s", second.Code, second.Body.String())
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected %s header on the replay", IdempotentReplayedHeader)
+	}
+}
+
+func TestIdempotency_RejectsReusedKey(t *testing.T) {
+	calls := 0
+	r := idempotentRouter(newMemoryIdempotencyRepo(), &calls, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	postIdempotent(r, context.Background(), "key-1", `{"amount": 10}`)
+	w := postIdempotent(r, context.Background(), "key-1", `{"amount": 20}`)
+
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), string(apperrors.CodeIdempotencyKeyReused)) {
+		t.Errorf("Expected 422 %s, got %d %s", apperrors.CodeIdempotencyKeyReused, w.Code, w.Body.String())
+	}
+	if calls != 1 {
+		t.Errorf("Expected the handler to run once, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_ReleasesKeyOnFailure(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		fail func(c *gin.Context)
+		want int
+	}{
+		{"server error", context.Background(), func(c *gin.Context) { handler.RespondError(c, apperrors.New(apperrors.CodeInternal, "boom")) }, http.StatusInternalServerError},
+		{"timeout", context.Background(), func(c *gin.Context) { handler.RespondError(c, apperrors.ErrRequestTimeout) }, http.StatusServiceUnavailable},
+		{"client closed request", context.Background(), func(c *gin.Context) { handler.RespondError(c, apperrors.ErrRequestCanceled) }, handler.StatusClientClosedRequest},
+		{"refused after cancel", canceled, func(c *gin.Context) { handler.RespondError(c, apperrors.ErrInsufficientFunds) }, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		repo := newMemoryIdempotencyRepo()
+		calls := 0
+		r := idempotentRouter(repo, &calls, func(c *gin.Context) {
+			if calls == 1 {
+				tt.fail(c)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{})
+		})
+
+		if w := postIdempotent(r, tt.ctx, "key-1", `{}`); w.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, w.Code)
+		}
+		w := postIdempotent(r, context.Background(), "key-1", `{}`)
+		if calls != 2 || w.Code != http.StatusOK || w.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Errorf("%s: expected the retry to run again, got %d after %d calls", tt.name, w.Code, calls)
+		}
+	}
+}
+
+func TestIdempotency_StoresClientErrors(t *testing.T) {
+	repo := newMemoryIdempotencyRepo()
+	calls := 0
+	r := idempotentRouter(repo, &calls, func(c *gin.Context) {
+		handler.RespondError(c, apperrors.ErrInsufficientFunds)
+	})
+
+	postIdempotent(r, context.Background(), "key-1", `{}`)
+	w := postIdempotent(r, context.Background(), "key-1", `{}`)
+
+	if calls != 1 || w.Code != http.StatusBadRequest || w.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Errorf("Expected the refusal replayed, got %d after %d calls", w.Code, calls)
+	}
+}
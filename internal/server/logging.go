@@ -0,0 +1,87 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"wallet_service/handler"
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// probeRoutes are polled by the orchestrator and Prometheus; their access
+// logs are only written at debug level.
+var probeRoutes = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// RequestID accepts the caller's X-Request-ID or generates one, echoes it in
+// the response and stores it in the request context for logs and errors.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// validRequestID accepts short IDs of visible ASCII characters, so that a
+// client cannot inject arbitrary content into logs.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	return !strings.ContainsFunc(requestID, func(r rune) bool {
+		return r <= ' ' || r > '~'
+	})
+}
+
+// AccessLog writes one structured record per request.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		level := slog.LevelInfo
+		if probeRoutes[c.FullPath()] {
+			level = slog.LevelDebug
+		}
+		slog.Log(c.Request.Context(), level, "request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", c.Writer.Status(),
+			"duration_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
+
+// Recovery turns a panic into a logged internal error response.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "panic while handling request",
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"panic", recovered,
+		)
+		if !c.Writer.Written() {
+			handler.RespondError(c, apperrors.New(apperrors.CodeInternal, "internal server error"))
+			return
+		}
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wallet_service/handler"
+	"wallet_service/internal/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRequestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/fail", func(c *gin.Context) {
+		handler.RespondError(c, apperrors.ErrWalletNotFound)
+	})
+	return r
+}
+
+func TestRequestID_EchoesClientID(t *testing.T) {
+	r := newRequestIDRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(RequestIDHeader, "client-id-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get(RequestIDHeader); got != "client-id-1" {
+		t.Errorf("Expected request ID header client-id-1, got %q", got)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode body: %v", err)
+	}
+	if body["request_id"] != "client-id-1" {
+		t.Errorf("Expected request ID in error body, got %v", body)
+	}
+}
+
+func TestRequestID_ReplacesInvalidID(t *testing.T) {
+	r := newRequestIDRouter()
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set(RequestIDHeader, "bad id\nwith newline")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	got := w.Header().Get(RequestIDHeader)
+	if got == "" || got == req.Header.Get(RequestIDHeader) {
+		t.Errorf("Expected a generated request ID, got %q", got)
+	}
+}
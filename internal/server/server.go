@@ -1,11 +1,26 @@
 package server
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"wallet_service/config"
 	"wallet_service/handler"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/exchange"
+	"wallet_service/internal/fees"
+	"wallet_service/internal/limits"
+	"wallet_service/internal/lock"
+	"wallet_service/internal/metrics"
+	"wallet_service/internal/models"
 	"wallet_service/internal/repository"
 	"wallet_service/internal/service"
 
@@ -16,14 +31,43 @@ import (
 )
 
 type Server struct {
-	DB            *sqlx.DB
-	WalletRepo    repository.WalletRepositoryInterface
-	WalletService *service.WalletService
-	WalletHandler *handler.WalletHandler
-	Router        *gin.Engine
+	DB              *sqlx.DB
+	WalletRepo      repository.WalletRepositoryInterface
+	IdempotencyRepo repository.IdempotencyRepositoryInterface
+	WalletService   *service.WalletService
+	WalletHandler   *handler.WalletHandler
+	Router          *gin.Engine
+
+	cfg               config.ServerConfig
+	startedAt         time.Time
+	expectedMigration int64
+	ready             atomic.Bool
+	inflight          sync.WaitGroup
+	workers           sync.WaitGroup
+	stopWorkers       context.CancelFunc
 }
 
 func NewServer(cfg *config.Config) (*Server, error) {
+	defaultCurrency, err := models.ParseCurrency(cfg.Wallet.DefaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid default currency %q: %w", cfg.Wallet.DefaultCurrency, err)
+	}
+
+	rates, err := exchange.LoadStaticRateProvider(cfg.Wallet.RatesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load exchange rates: %w", err)
+	}
+
+	feeSchedule, err := fees.Load(cfg.Wallet.FeesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fee schedule: %w", err)
+	}
+
+	limitPolicy, err := limits.Load(cfg.Wallet.LimitsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load limits: %w", err)
+	}
+
 	// Connect to database
 	dbConnStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Database.Host, cfg.Database.Port, cfg.Database.User, cfg.Database.Password, cfg.Database.DBName, cfg.Database.SSLMode)
@@ -32,42 +76,269 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	metrics.RegisterDBStats(db.DB)
 
 	// Run migrations with goose
 	if err := runMigrations(db); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
+	expectedMigration, err := latestMigrationVersion()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	locker, err := newLocker(cfg.Wallet.Locker)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 
 	// Initialize layers
 	walletRepo := repository.NewWalletRepository(db)
-	walletService := service.NewWalletService(walletRepo)
-	walletHandler := handler.NewWalletHandler(walletService)
+	walletRepo.FrozenDeposits = cfg.Wallet.FrozenDeposits
+	walletRepo.Limits = limitPolicy
+	if err := checkRevenueWallets(context.Background(), walletRepo, feeSchedule); err != nil {
+		db.Close()
+		return nil, err
+	}
+	walletService := service.NewWalletService(walletRepo, service.Options{
+		Locker:     locker,
+		Rates:      rates,
+		QuoteTTL:   cfg.Wallet.QuoteTTL,
+		HoldTTL:    cfg.Wallet.HoldDefaultTTL,
+		HoldMaxTTL: cfg.Wallet.HoldMaxTTL,
+		Fees:       feeSchedule,
+	})
+	walletHandler := handler.NewWalletHandler(walletService, defaultCurrency)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	tokens, err := newTokenAuthenticator(cfg.Auth)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db), cfg.Auth.AdminKey)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+
+	server := &Server{
+		DB:                db,
+		WalletRepo:        walletRepo,
+		IdempotencyRepo:   idempotencyRepo,
+		WalletService:     walletService,
+		WalletHandler:     walletHandler,
+		cfg:               cfg.Server,
+		startedAt:         time.Now(),
+		expectedMigration: expectedMigration,
+	}
 
 	// Setup Gin router
-	r := gin.Default()
-	api := r.Group("/api/v1")
+	r := gin.New()
+	r.Use(RequestID(), Tracing(), AccessLog(), Recovery(), Metrics())
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	r.GET("/healthz", server.Healthz)
+	r.GET("/readyz", server.Readyz)
+	r.GET("/status", server.Status)
+	api := r.Group("/api/v1", server.trackInflight, RequestTimeout(cfg.Server.RequestTimeout), Authenticate(apiKeyService, tokens))
 	{
-		api.POST("/wallets", walletHandler.CreateWallet)
-		api.POST("/wallet", walletHandler.PerformWalletOperation)
-		api.GET("/wallets/:wallet_uuid", walletHandler.GetWalletBalance)
+		idempotent := Idempotency(idempotencyRepo, cfg.Server.RequestTimeout)
+		readWallets := RequireScope(auth.ScopeWalletsRead)
+		writeWallets := RequireScope(auth.ScopeWalletsWrite)
+		writeTransfers := RequireScope(auth.ScopeTransfersWrite)
+
+		api.POST("/wallets", writeWallets, idempotent, walletHandler.CreateWallet)
+		api.POST("/wallet", writeWallets, idempotent, walletHandler.PerformWalletOperation)
+		api.POST("/wallet/batch", writeWallets, idempotent, walletHandler.ExecuteBatch)
+		api.POST("/transfers", writeTransfers, idempotent, walletHandler.Transfer)
+		api.POST("/transfers/quotes", writeTransfers, walletHandler.CreateQuote)
+		api.POST("/transactions/:transaction_id/reversals", writeWallets, idempotent, walletHandler.ReverseTransaction)
+		api.GET("/wallets", readWallets, walletHandler.ListWallets)
+		api.GET("/wallets/:wallet_uuid", readWallets, walletHandler.GetWalletBalance)
+		api.GET("/wallets/:wallet_uuid/transactions", readWallets, walletHandler.GetTransactions)
+		api.GET("/wallets/:wallet_uuid/status-changes", readWallets, walletHandler.GetWalletStatusChanges)
+		api.POST("/wallets/:wallet_uuid/freeze", writeWallets, walletHandler.FreezeWallet)
+		api.POST("/wallets/:wallet_uuid/unfreeze", writeWallets, walletHandler.UnfreezeWallet)
+		api.POST("/wallets/:wallet_uuid/close", writeWallets, walletHandler.CloseWallet)
+		api.GET("/wallets/:wallet_uuid/limits", readWallets, walletHandler.GetWalletLimits)
+		api.PUT("/wallets/:wallet_uuid/limits", writeWallets, walletHandler.SetWalletLimits)
+		api.PUT("/wallets/:wallet_uuid/overdraft", writeWallets, walletHandler.SetCreditLimit)
+		api.GET("/wallets/:wallet_uuid/overdraft-periods", readWallets, walletHandler.GetOverdraftPeriods)
+		api.POST("/wallets/:wallet_uuid/holds", writeWallets, idempotent, walletHandler.CreateHold)
+		api.GET("/holds/:hold_id", readWallets, walletHandler.GetHold)
+		api.POST("/holds/:hold_id/capture", writeWallets, idempotent, walletHandler.CaptureHold)
+		api.POST("/holds/:hold_id/void", writeWallets, walletHandler.VoidHold)
+
+		admin := api.Group("/admin", RequireScope(auth.ScopeAdmin))
+		admin.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+		admin.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+		admin.DELETE("/api-keys/:key_id", apiKeyHandler.RevokeAPIKey)
 	}
 
-	server := &Server{
-		DB:            db,
-		WalletRepo:    walletRepo,
-		WalletService: walletService,
-		WalletHandler: walletHandler,
-		Router:        r,
+	server.Router = r
+
+	workers, stopWorkers := context.WithCancel(context.Background())
+	server.stopWorkers = stopWorkers
+	if interval := cfg.Wallet.HoldExpiryInterval; interval > 0 {
+		server.workers.Add(1)
+		go func() {
+			defer server.workers.Done()
+			walletService.RunHoldExpiry(workers, interval)
+		}()
+	}
+	if interval := cfg.Server.IdempotencyCleanupInterval; interval > 0 {
+		server.workers.Add(1)
+		go func() {
+			defer server.workers.Done()
+			RunIdempotencyCleanup(workers, idempotencyRepo, cfg.Server.IdempotencyKeyTTL, interval)
+		}()
 	}
 
 	return server, nil
 }
 
+// Run serves HTTP until ctx is done and then shuts down gracefully: the
+// server reports not-ready, keeps serving for ShutdownDelay, stops accepting
+// connections and waits up to DrainTimeout for in-flight requests. Requests
+// still running after that are cancelled. The database is closed only once
+// every handler has returned.
+func (s *Server) Run(ctx context.Context) error {
+	httpServer := &http.Server{
+		Addr:              ":" + s.cfg.Port,
+		Handler:           s.Router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+	s.ready.Store(true)
+	slog.Info("Server starting", "addr", httpServer.Addr)
+
+	select {
+	case err := <-serveErr:
+		s.Close()
+		return fmt.Errorf("failed to start server: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutdown requested, reporting not ready")
+	s.ready.Store(false)
+	time.Sleep(s.cfg.ShutdownDelay)
+
+	slog.Info("Draining in-flight requests")
+	drainCtx, cancel := context.WithTimeout(context.Background(), s.cfg.DrainTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(drainCtx); err != nil {
+		// Closing the connections cancels the request contexts, which rolls
+		// back the transactions still in progress
+		slog.Warn("Drain timeout exceeded, closing remaining connections", "error", err)
+		httpServer.Close()
+	}
+	s.inflight.Wait()
+
+	if err := s.Close(); err != nil {
+		return fmt.Errorf("failed to close database: %w", err)
+	}
+	slog.Info("Server stopped")
+	return nil
+}
+
+// Close stops the background workers, waits for them to return and closes
+// the database.
+func (s *Server) Close() error {
+	s.stopWorkers()
+	s.workers.Wait()
+	return s.DB.Close()
+}
+
+// trackInflight counts running API requests so that shutdown can wait for
+// them before closing the database.
+func (s *Server) trackInflight(c *gin.Context) {
+	s.inflight.Add(1)
+	defer s.inflight.Done()
+	c.Next()
+}
+
+func newLocker(backend string) (lock.Locker, error) {
+	switch backend {
+	case lock.BackendMemory:
+		return lock.NewMemoryLocker(), nil
+	case lock.BackendPostgres:
+		return lock.NewPostgresLocker(), nil
+	default:
+		return nil, fmt.Errorf("unknown wallet locker %q, expected %q or %q", backend, lock.BackendMemory, lock.BackendPostgres)
+	}
+}
+
+// checkRevenueWallets makes sure every revenue wallet of the fee schedule
+// exists, is not closed and holds its currency. Otherwise every operation
+// charged a fee in that currency would fail until the schedule is fixed.
+func checkRevenueWallets(ctx context.Context, repo repository.WalletRepositoryInterface, schedule *fees.Schedule) error {
+	revenueWallets := schedule.RevenueWallets()
+	if len(revenueWallets) == 0 {
+		return nil
+	}
+
+	wallets, err := repo.GetWallets(ctx, slices.Collect(maps.Values(revenueWallets)))
+	if err != nil {
+		return fmt.Errorf("failed to load revenue wallets: %w", err)
+	}
+	for currency, walletID := range revenueWallets {
+		wallet, ok := wallets[walletID]
+		switch {
+		case !ok:
+			return fmt.Errorf("revenue wallet %s for %s not found", walletID, currency)
+		case wallet.Status == models.WalletClosed:
+			return fmt.Errorf("revenue wallet %s for %s is closed", walletID, currency)
+		case wallet.Currency != currency:
+			return fmt.Errorf("revenue wallet %s for %s holds %s", walletID, currency, wallet.Currency)
+		}
+	}
+	return nil
+}
+
+// newTokenAuthenticator returns the verifier of bearer tokens, or nil when no
+// JWT key is configured.
+func newTokenAuthenticator(cfg config.AuthConfig) (Authenticator, error) {
+	if cfg.JWTSecret == "" && cfg.JWTPublicKeyFile == "" {
+		return nil, nil
+	}
+
+	var publicKey []byte
+	if cfg.JWTPublicKeyFile != "" {
+		var err error
+		if publicKey, err = os.ReadFile(cfg.JWTPublicKeyFile); err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+	}
+	verifier, err := auth.NewJWTVerifier([]byte(cfg.JWTSecret), publicKey, cfg.JWTIssuer, cfg.JWTAudience)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure JWT authentication: %w", err)
+	}
+	return verifier, nil
+}
+
+// latestMigrationVersion returns the version the database is expected to be
+// at once all bundled migrations have been applied.
+func latestMigrationVersion() (int64, error) {
+	migrations, err := goose.CollectMigrations(migrationsDir, 0, goose.MaxVersion)
+	if err != nil {
+		return 0, fmt.Errorf("failed to collect migrations: %w", err)
+	}
+	last, err := migrations.Last()
+	if err != nil {
+		return 0, fmt.Errorf("failed to find latest migration: %w", err)
+	}
+	return last.Version, nil
+}
+
 func runMigrations(db *sqlx.DB) error {
 	goose.SetDialect("postgres")
-	if err := goose.Up(db.DB, "./migrations"); err != nil {
+	if err := goose.Up(db.DB, migrationsDir); err != nil {
 		return fmt.Errorf("failed to run goose migrations: %w", err)
 	}
-	log.Println("Migrations completed successfully")
+	slog.Info("Migrations completed successfully")
 	return nil
 }
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"wallet_service/internal/fees"
+	"wallet_service/internal/models"
+	"wallet_service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// walletsRepo serves GetWallets from a map; other methods are not used.
+type walletsRepo struct {
+	repository.WalletRepositoryInterface
+	wallets map[uuid.UUID]*models.Wallet
+}
+
+func (r walletsRepo) GetWallets(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Wallet, error) {
+	found := map[uuid.UUID]*models.Wallet{}
+	for _, id := range ids {
+		if wallet, ok := r.wallets[id]; ok {
+			found[id] = wallet
+		}
+	}
+	return found, nil
+}
+
+func TestCheckRevenueWallets(t *testing.T) {
+	usd, eur, closed, missing := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	repo := walletsRepo{wallets: map[uuid.UUID]*models.Wallet{
+		usd:    {ID: usd, Currency: "USD", Status: models.WalletActive},
+		eur:    {ID: eur, Currency: "EUR", Status: models.WalletFrozen},
+		closed: {ID: closed, Currency: "GBP", Status: models.WalletClosed},
+	}}
+
+	tests := []struct {
+		name    string
+		wallets map[models.Currency]uuid.UUID
+		want    string
+	}{
+		{"no fees", nil, ""},
+		{"valid", map[models.Currency]uuid.UUID{"USD": usd, "EUR": eur}, ""},
+		{"missing", map[models.Currency]uuid.UUID{"USD": missing}, "not found"},
+		{"closed", map[models.Currency]uuid.UUID{"GBP": closed}, "is closed"},
+		{"wrong currency", map[models.Currency]uuid.UUID{"EUR": usd}, "holds USD"},
+	}
+
+	for _, tt := range tests {
+		schedule, err := fees.NewSchedule(fees.Config{RevenueWallets: tt.wallets})
+		if err != nil {
+			t.Fatalf("%s: failed to build schedule: %v", tt.name, err)
+		}
+		err = checkRevenueWallets(context.Background(), repo, schedule)
+		if tt.want == "" && err != nil {
+			t.Errorf("%s: expected no error, got %v", tt.name, err)
+		}
+		if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
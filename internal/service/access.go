@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var errOwnerRequired = apperrors.New(apperrors.CodeForbidden, "credentials are not bound to an owner")
+
+// authorizeWallet checks that the principal of ctx may act on the wallet.
+// Wallets of other owners are reported as notFound so that their existence
+// is not revealed. Calls without a principal come from within the service,
+// e.g. background workers, and are always allowed.
+func authorizeWallet(ctx context.Context, wallet *models.Wallet, notFound error) error {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil || principal.CanAccess(wallet.OwnerID) {
+		return nil
+	}
+	return notFound
+}
+
+// checkWalletAccess loads the wallet only when the principal of ctx is
+// restricted to its own wallets and authorizes it.
+func (s *WalletService) checkWalletAccess(ctx context.Context, walletID uuid.UUID, notFound error) error {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil || principal.IsAdmin() {
+		return nil
+	}
+
+	wallet, err := s.repo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrWalletNotFound) {
+			return notFound
+		}
+		return err
+	}
+	return authorizeWallet(ctx, wallet, notFound)
+}
+
+// walletOwner returns the owner of a wallet created by the principal of ctx.
+// Admins may create wallets for any owner, or without one; everyone else
+// creates wallets they own.
+func walletOwner(ctx context.Context, requested *string) (*string, error) {
+	principal := auth.PrincipalFrom(ctx)
+	if principal == nil || principal.IsAdmin() {
+		return requested, nil
+	}
+	if principal.OwnerID == "" {
+		return nil, errOwnerRequired
+	}
+	if requested != nil && *requested != principal.OwnerID {
+		return nil, apperrors.ErrForbidden
+	}
+	return &principal.OwnerID, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func userContext(ownerID string) context.Context {
+	return auth.WithPrincipal(context.Background(), &auth.Principal{ID: "user:" + ownerID, OwnerID: ownerID})
+}
+
+func TestWalletService_GetWalletBalance_OtherOwner(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	owner := "customer-2"
+	walletID := uuid.New()
+	mockRepo.On("GetWalletByID", walletID).Return(&models.Wallet{ID: walletID, OwnerID: &owner}, nil)
+
+	_, err := service.GetWalletBalance(userContext("customer-1"), walletID)
+	if !errors.Is(err, apperrors.ErrWalletNotFound) {
+		t.Errorf("Expected wallet not found error, got %v", err)
+	}
+
+	if _, err := service.GetWalletBalance(userContext(owner), walletID); err != nil {
+		t.Errorf("Expected owner to read the wallet, got %v", err)
+	}
+}
+
+func TestWalletService_PerformWalletOperation_OtherOwner(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	owner := "customer-2"
+	walletID := uuid.New()
+	mockRepo.On("GetWalletByID", walletID).Return(&models.Wallet{ID: walletID, OwnerID: &owner}, nil)
+
+	_, _, err := service.PerformWalletOperation(userContext("customer-1"), walletID, models.WITHDRAW, models.NewAmount(10, 0), testCurrency)
+	if !errors.Is(err, apperrors.ErrWalletNotFound) {
+		t.Errorf("Expected wallet not found error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "PerformOperation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWalletService_Transfer_AdminActsOnAnyWallet(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	fromWalletID, toWalletID := uuid.New(), uuid.New()
+	amount := models.NewAmount(10, 0)
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: testCurrency}, nil)
+	mockRepo.On("Transfer", fromWalletID, toWalletID, amount, testCurrency, (*models.CurrencyConversion)(nil), (*models.Fee)(nil)).
+		Return(&models.Transfer{ID: uuid.New()}, nil)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "backoffice", Roles: []auth.Role{auth.RoleAdmin}})
+	if _, err := service.Transfer(ctx, fromWalletID, toWalletID, amount, testCurrency, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The source wallet is not loaded for admins
+	mockRepo.AssertNotCalled(t, "GetWalletByID", fromWalletID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_CreateWallet_Owner(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	owner := "customer-1"
+	mockRepo.On("CreateWallet", testCurrency, &owner).Return(&models.Wallet{OwnerID: &owner}, nil)
+
+	if _, err := service.CreateWallet(userContext(owner), testCurrency, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	other := "customer-2"
+	if _, err := service.CreateWallet(userContext(owner), testCurrency, &other); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+
+	serviceAccount := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "key"})
+	if _, err := service.CreateWallet(serviceAccount, testCurrency, nil); apperrors.CodeOf(err) != apperrors.CodeForbidden {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+
+	mockRepo.AssertNumberOfCalls(t, "CreateWallet", 1)
+}
+
+func TestWalletService_ListWallets_RestrictedToOwner(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	owner := "customer-1"
+	page := &models.WalletPage{}
+	mockRepo.On("ListWallets", models.WalletFilter{OwnerID: &owner, Limit: 10}).Return(page, nil)
+
+	result, err := service.ListWallets(userContext(owner), models.WalletFilter{Limit: 10})
+	if err != nil || result != page {
+		t.Fatalf("Expected the owner's page, got %v, %v", result, err)
+	}
+
+	other := "customer-2"
+	if _, err := service.ListWallets(userContext(owner), models.WalletFilter{OwnerID: &other}); !errors.Is(err, apperrors.ErrForbidden) {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+
+	mockRepo.AssertNumberOfCalls(t, "ListWallets", 1)
+}
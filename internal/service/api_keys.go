@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+	"wallet_service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// adminPrincipalID identifies requests made with the configured admin key.
+const adminPrincipalID = "admin"
+
+// APIKeyService issues API keys and authenticates requests made with them.
+type APIKeyService struct {
+	repo repository.APIKeyRepositoryInterface
+	// adminKeyHash is the hash of the configured admin key, which can create
+	// the first keys; empty if none is configured
+	adminKeyHash string
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepositoryInterface, adminKey string) *APIKeyService {
+	s := &APIKeyService{repo: repo}
+	if adminKey != "" {
+		s.adminKeyHash = auth.HashKey(adminKey)
+	}
+	return s
+}
+
+// CreateAPIKey issues a new key with the given scopes. The key itself is
+// returned only here.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req models.CreateAPIKeyRequest) (*models.CreatedAPIKey, error) {
+	name, scopes := strings.TrimSpace(req.Name), req.Scopes
+	if name == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidRequest, "name is required")
+	}
+	if len(scopes) == 0 {
+		return nil, apperrors.New(apperrors.CodeInvalidRequest, "at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if _, err := auth.ParseScope(scope); err != nil {
+			return nil, apperrors.New(apperrors.CodeInvalidRequest, err.Error())
+		}
+	}
+
+	for _, role := range req.Roles {
+		if _, err := auth.ParseRole(role); err != nil {
+			return nil, apperrors.New(apperrors.CodeInvalidRequest, err.Error())
+		}
+	}
+	if req.OwnerID != nil && strings.TrimSpace(*req.OwnerID) == "" {
+		return nil, apperrors.New(apperrors.CodeInvalidRequest, "ownerId must not be empty")
+	}
+
+	secret, err := auth.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	key := &models.APIKey{
+		ID:      uuid.New(),
+		Name:    name,
+		Prefix:  auth.KeyDisplayPrefix(secret),
+		OwnerID: req.OwnerID,
+		Scopes:  scopes,
+		Roles:   req.Roles,
+	}
+	if key.Roles == nil {
+		key.Roles = []string{}
+	}
+	if err := s.repo.CreateAPIKey(ctx, key, auth.HashKey(secret)); err != nil {
+		return nil, err
+	}
+	return &models.CreatedAPIKey{APIKey: key, Key: secret}, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.repo.ListAPIKeys(ctx)
+}
+
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	return s.repo.RevokeAPIKey(ctx, id)
+}
+
+// Authenticate returns the principal of an API key. Unknown and revoked keys
+// are reported as ErrUnauthorized.
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*auth.Principal, error) {
+	hash := auth.HashKey(secret)
+	if s.adminKeyHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(s.adminKeyHash)) == 1 {
+		return &auth.Principal{ID: adminPrincipalID, Name: adminPrincipalID, Scopes: []auth.Scope{auth.ScopeAdmin}}, nil
+	}
+
+	key, err := s.repo.GetActiveAPIKey(ctx, hash)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrAPIKeyNotFound) {
+			return nil, apperrors.ErrUnauthorized
+		}
+		return nil, err
+	}
+
+	principal := &auth.Principal{ID: key.ID.String(), Name: key.Name}
+	if key.OwnerID != nil {
+		principal.OwnerID = *key.OwnerID
+	}
+	for _, scope := range key.Scopes {
+		principal.Scopes = append(principal.Scopes, auth.Scope(scope))
+	}
+	for _, role := range key.Roles {
+		principal.Roles = append(principal.Roles, auth.Role(role))
+	}
+	return principal, nil
+}
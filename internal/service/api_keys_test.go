@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	args := m.Called(key, keyHash)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetActiveAPIKey(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	args := m.Called(keyHash)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	args := m.Called()
+	return args.Get(0).([]models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func TestAPIKeyService_CreateAndAuthenticate(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, "")
+
+	var storedHash string
+	mockRepo.On("CreateAPIKey", mock.AnythingOfType("*models.APIKey"), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { storedHash = args.String(1) }).
+		Return(nil)
+
+	created, err := service.CreateAPIKey(context.Background(), models.CreateAPIKeyRequest{
+		Name: "billing", Scopes: []string{"wallets:read"}, Roles: []string{"admin"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, auth.HashKey(created.Key), storedHash)
+	assert.NotContains(t, storedHash, created.Key)
+	assert.Equal(t, auth.KeyDisplayPrefix(created.Key), created.Prefix)
+
+	mockRepo.On("GetActiveAPIKey", storedHash).Return(created.APIKey, nil)
+	principal, err := service.Authenticate(context.Background(), created.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, created.ID.String(), principal.ID)
+	assert.True(t, principal.HasScope(auth.ScopeWalletsRead))
+	assert.False(t, principal.HasScope(auth.ScopeWalletsWrite))
+	assert.True(t, principal.IsAdmin())
+}
+
+func TestAPIKeyService_CreateAPIKey_UnknownScope(t *testing.T) {
+	service := NewAPIKeyService(new(MockAPIKeyRepository), "")
+
+	_, err := service.CreateAPIKey(context.Background(), models.CreateAPIKeyRequest{Name: "billing", Scopes: []string{"wallets:delete"}})
+	assert.Equal(t, apperrors.CodeInvalidRequest, apperrors.CodeOf(err))
+
+	_, err = service.CreateAPIKey(context.Background(), models.CreateAPIKeyRequest{
+		Name: "billing", Scopes: []string{"wallets:read"}, Roles: []string{"root"},
+	})
+	assert.Equal(t, apperrors.CodeInvalidRequest, apperrors.CodeOf(err))
+}
+
+func TestAPIKeyService_Authenticate_UnknownKey(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, "")
+	mockRepo.On("GetActiveAPIKey", auth.HashKey("wsk_unknown")).Return((*models.APIKey)(nil), apperrors.ErrAPIKeyNotFound)
+
+	_, err := service.Authenticate(context.Background(), "wsk_unknown")
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+func TestAPIKeyService_Authenticate_AdminKey(t *testing.T) {
+	mockRepo := new(MockAPIKeyRepository)
+	service := NewAPIKeyService(mockRepo, "bootstrap-secret")
+
+	principal, err := service.Authenticate(context.Background(), "bootstrap-secret")
+	assert.NoError(t, err)
+	assert.True(t, principal.HasScope(auth.ScopeAdmin))
+	assert.False(t, principal.HasScope(auth.ScopeWalletsWrite))
+	mockRepo.AssertNotCalled(t, "GetActiveAPIKey", mock.Anything)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/fees"
+	"wallet_service/internal/metrics"
+	"wallet_service/internal/models"
+	"wallet_service/internal/repository"
+	"wallet_service/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	errInvalidBatchMode = apperrors.New(apperrors.CodeInvalidRequest, "mode must be atomic or best_effort")
+	errBatchSize        = apperrors.New(apperrors.CodeInvalidRequest, fmt.Sprintf("a batch must have between 1 and %d items", models.MaxBatchSize))
+)
+
+// ExecuteBatch runs the deposits, withdrawals and transfers of req in order.
+// An atomic batch is booked in one database transaction and stops at the
+// first item that is refused, which is reported in the result along with the
+// items rolled back; internal errors are returned instead. A best-effort
+// batch executes every item as if requested on its own.
+func (s *WalletService) ExecuteBatch(ctx context.Context, req models.BatchRequest) (result *models.BatchResult, err error) {
+	if req.Mode == "" {
+		req.Mode = models.BatchAtomic
+	}
+	ctx, span := tracing.Start(ctx, "WalletService.ExecuteBatch",
+		attribute.String("batch.mode", string(req.Mode)), attribute.Int("batch.size", len(req.Items)))
+	defer func() { tracing.End(span, err) }()
+
+	if req.Mode != models.BatchAtomic && req.Mode != models.BatchBestEffort {
+		return nil, errInvalidBatchMode
+	}
+	if len(req.Items) == 0 || len(req.Items) > models.MaxBatchSize {
+		return nil, errBatchSize
+	}
+	// Transfers need the scope of POST /api/v1/transfers as well
+	if principal := auth.PrincipalFrom(ctx); principal != nil && !principal.HasScope(auth.ScopeTransfersWrite) {
+		for _, item := range req.Items {
+			if item.OperationType == models.TRANSFER {
+				return nil, apperrors.ErrForbidden
+			}
+		}
+	}
+
+	if req.Mode == models.BatchBestEffort {
+		return s.executeBestEffort(ctx, req.Items), nil
+	}
+	return s.executeAtomic(ctx, req.Items)
+}
+
+func (s *WalletService) executeBestEffort(ctx context.Context, items []models.BatchItem) *models.BatchResult {
+	result := &models.BatchResult{Mode: models.BatchBestEffort, Results: make([]models.BatchItemResult, len(items))}
+	for i, item := range items {
+		itemResult := models.BatchItemResult{Index: i, Status: models.BatchItemSucceeded}
+		var err error
+		switch item.OperationType {
+		case models.DEPOSIT, models.WITHDRAW:
+			itemResult.Transaction, itemResult.Fee, err = s.PerformWalletOperation(ctx, item.WalletID, item.OperationType, item.Amount, item.Currency)
+		case models.TRANSFER:
+			itemResult.Transfer, err = s.Transfer(ctx, item.FromWalletID, item.ToWalletID, item.Amount, item.Currency, nil)
+		default:
+			err = apperrors.ErrInvalidOperationType
+		}
+		if err != nil {
+			itemResult.Status, itemResult.Error = models.BatchItemFailed, batchError(ctx, err)
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+		result.Results[i] = itemResult
+	}
+	logOutcome(ctx, "Batch", nil, "mode", result.Mode, "succeeded", result.Succeeded, "failed", result.Failed)
+	return result
+}
+
+func (s *WalletService) executeAtomic(ctx context.Context, items []models.BatchItem) (*models.BatchResult, error) {
+	var walletIDs []uuid.UUID
+	for _, item := range items {
+		walletIDs = append(walletIDs, item.WalletID, item.FromWalletID, item.ToWalletID)
+	}
+	// Items use either WalletID or the transfer wallets, never both
+	walletIDs = slices.DeleteFunc(walletIDs, func(id uuid.UUID) bool { return id == uuid.Nil })
+
+	// Every item is checked against one snapshot of its wallets rather than
+	// looking them up one by one; the repository checks them again under lock
+	wallets, err := s.repo.GetWallets(ctx, walletIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ops := make([]repository.BatchOperation, len(items))
+	for i, item := range items {
+		op, err := s.prepareBatchItem(ctx, item, wallets)
+		if err != nil {
+			if apperrors.CodeOf(err) == apperrors.CodeInternal {
+				return nil, err
+			}
+			return atomicFailure(len(items), i, batchError(ctx, err)), nil
+		}
+		ops[i] = op
+	}
+
+	ctx, unlock, err := s.lockWallets(ctx, walletIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	results, err := s.repo.ExecuteBatch(ctx, ops)
+	logOutcome(ctx, "Batch", err, "mode", models.BatchAtomic, "items", len(items))
+	if err != nil {
+		var itemErr *repository.BatchItemError
+		if !errors.As(err, &itemErr) || apperrors.CodeOf(itemErr.Err) == apperrors.CodeInternal {
+			return nil, err
+		}
+		operation := items[itemErr.Index].OperationType
+		if operation == models.TRANSFER {
+			operation = models.TRANSFER_OUT
+		}
+		observeRejection(string(operation), itemErr.Err)
+		return atomicFailure(len(items), itemErr.Index, batchError(ctx, itemErr.Err)), nil
+	}
+
+	for _, itemResult := range results {
+		if itemResult.Transfer != nil {
+			observeTransfer(itemResult.Transfer, nil)
+			continue
+		}
+		metrics.ObserveTransactions(*itemResult.Transaction)
+		if itemResult.Fee != nil {
+			metrics.ObserveTransactions(*itemResult.Fee.Transaction)
+		}
+	}
+	return &models.BatchResult{Mode: models.BatchAtomic, Succeeded: len(results), Results: results}, nil
+}
+
+// prepareBatchItem validates an item of an atomic batch against the batch's
+// wallets, checks access to the wallets it debits and prices its fee and
+// conversion, like PerformWalletOperation and Transfer do.
+func (s *WalletService) prepareBatchItem(ctx context.Context, item models.BatchItem, wallets map[uuid.UUID]*models.Wallet) (repository.BatchOperation, error) {
+	op := repository.BatchOperation{Item: item}
+	currency := item.Currency
+	// Like PerformWalletOperation and Transfer, in the currency of the wallet
+	// deposited to or debited if none is given
+	if wallet, ok := wallets[cmp.Or(item.WalletID, item.FromWalletID)]; ok && currency == "" {
+		currency = wallet.Currency
+	}
+	currency, err := validateMoney(item.Amount, currency)
+	if err != nil {
+		return op, err
+	}
+	op.Item.Currency = currency
+
+	switch item.OperationType {
+	case models.DEPOSIT, models.WITHDRAW:
+		wallet, ok := wallets[item.WalletID]
+		if !ok {
+			return op, apperrors.ErrWalletNotFound
+		}
+		if err := authorizeWallet(ctx, wallet, apperrors.ErrWalletNotFound); err != nil {
+			return op, err
+		}
+		if item.OperationType == models.WITHDRAW {
+			op.Fee, err = s.feeFor(fees.Withdrawal, item.WalletID, item.Amount, currency)
+		}
+	case models.TRANSFER:
+		if item.FromWalletID == item.ToWalletID {
+			return op, apperrors.ErrSameWallet
+		}
+		from, ok := wallets[item.FromWalletID]
+		if !ok {
+			return op, apperrors.ErrSourceWalletNotFound
+		}
+		if err := authorizeWallet(ctx, from, apperrors.ErrSourceWalletNotFound); err != nil {
+			return op, err
+		}
+		to, ok := wallets[item.ToWalletID]
+		if !ok {
+			return op, apperrors.ErrDestinationWalletNotFound
+		}
+		if to.Currency != currency {
+			if op.Conversion, err = s.convert(ctx, item.Amount, currency, to.Currency); err != nil {
+				return op, err
+			}
+		}
+		op.Fee, err = s.feeFor(fees.Transfer, item.FromWalletID, item.Amount, currency)
+	default:
+		return op, apperrors.ErrInvalidOperationType
+	}
+	return op, err
+}
+
+// atomicFailure reports an atomic batch of size items that failed at index.
+func atomicFailure(size, index int, err *models.BatchError) *models.BatchResult {
+	result := &models.BatchResult{Mode: models.BatchAtomic, Failed: 1, Results: make([]models.BatchItemResult, size)}
+	for i := range result.Results {
+		result.Results[i] = models.BatchItemResult{Index: i, Status: models.BatchItemRolledBack}
+	}
+	result.Results[index].Status, result.Results[index].Error = models.BatchItemFailed, err
+	return result
+}
+
+// batchError describes why an item failed the way RespondError would.
+func batchError(ctx context.Context, err error) *models.BatchError {
+	var appErr *apperrors.Error
+	switch {
+	case errors.As(err, &appErr):
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(ctx.Err(), context.DeadlineExceeded):
+		appErr = apperrors.ErrRequestTimeout
+	case errors.Is(err, context.Canceled), errors.Is(ctx.Err(), context.Canceled):
+		appErr = apperrors.ErrRequestCanceled
+	default:
+		appErr = apperrors.New(apperrors.CodeInternal, "internal server error")
+	}
+	return &models.BatchError{Code: appErr.Code, Message: appErr.Message}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+	"wallet_service/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWalletService_ExecuteBatch_BestEffort(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	walletID := uuid.New()
+	deposit, withdrawal := models.NewAmount(10, 0), models.NewAmount(50, 0)
+	mockRepo.On("PerformOperation", walletID, models.DEPOSIT, deposit, testCurrency, (*models.Fee)(nil)).
+		Return(&models.Transaction{ID: uuid.New(), WalletID: walletID, OperationType: models.DEPOSIT, Amount: deposit, Currency: testCurrency}, nil)
+	mockRepo.On("PerformOperation", walletID, models.WITHDRAW, withdrawal, testCurrency, (*models.Fee)(nil)).
+		Return((*models.Transaction)(nil), apperrors.ErrInsufficientFunds)
+
+	result, err := service.ExecuteBatch(context.Background(), models.BatchRequest{
+		Mode: models.BatchBestEffort,
+		Items: []models.BatchItem{
+			{OperationType: models.DEPOSIT, WalletID: walletID, Amount: deposit, Currency: testCurrency},
+			{OperationType: models.WITHDRAW, WalletID: walletID, Amount: withdrawal, Currency: testCurrency},
+			{OperationType: models.ADJUSTMENT, WalletID: walletID, Amount: deposit, Currency: testCurrency},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Succeeded != 1 || result.Failed != 2 {
+		t.Errorf("Expected 1 succeeded and 2 failed items, got %d and %d", result.Succeeded, result.Failed)
+	}
+	if result.Results[0].Status != models.BatchItemSucceeded || result.Results[0].Transaction == nil {
+		t.Errorf("Expected the deposit to succeed, got %+v", result.Results[0])
+	}
+	for i, code := range map[int]apperrors.Code{1: apperrors.CodeInsufficientFunds, 2: apperrors.CodeInvalidOperationType} {
+		if item := result.Results[i]; item.Status != models.BatchItemFailed || item.Error == nil || item.Error.Code != code {
+			t.Errorf("Expected item %d to fail with %s, got %+v", i, code, item)
+		}
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_ExecuteBatch_Atomic(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	fromID, toID := uuid.New(), uuid.New()
+	amount := models.NewAmount(5, 0)
+	items := []models.BatchItem{
+		{OperationType: models.DEPOSIT, WalletID: fromID, Amount: amount, Currency: "usd"},
+		{OperationType: models.WITHDRAW, WalletID: fromID, Amount: amount},
+		{OperationType: models.TRANSFER, FromWalletID: fromID, ToWalletID: toID, Amount: amount, Currency: testCurrency},
+	}
+	mockRepo.On("GetWallets", []uuid.UUID{fromID, fromID, fromID, toID}).Return(map[uuid.UUID]*models.Wallet{
+		fromID: {ID: fromID, Currency: testCurrency},
+		toID:   {ID: toID, Currency: testCurrency},
+	}, nil).Once()
+	mockRepo.On("ExecuteBatch", mock.Anything).Return([]models.BatchItemResult{
+		{Index: 0, Status: models.BatchItemSucceeded, Transaction: &models.Transaction{OperationType: models.DEPOSIT, Amount: amount, Currency: testCurrency}},
+		{Index: 1, Status: models.BatchItemSucceeded, Transaction: &models.Transaction{OperationType: models.WITHDRAW, Amount: amount, Currency: testCurrency}},
+		{Index: 2, Status: models.BatchItemSucceeded, Transfer: &models.Transfer{FromWalletID: fromID, ToWalletID: toID}},
+	}, nil)
+
+	result, err := service.ExecuteBatch(context.Background(), models.BatchRequest{Items: items})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Mode != models.BatchAtomic || result.Succeeded != 3 || result.Failed != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	ops := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(0).([]repository.BatchOperation)
+	if len(ops) != 3 || ops[0].Item.Currency != testCurrency || ops[1].Item.Currency != testCurrency || ops[2].Conversion != nil {
+		t.Errorf("Expected normalized items without conversion, got %+v", ops)
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetWalletByID", mock.Anything)
+}
+
+func TestWalletService_ExecuteBatch_AtomicItemRefused(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	walletID := uuid.New()
+	items := []models.BatchItem{
+		{OperationType: models.DEPOSIT, WalletID: walletID, Amount: models.NewAmount(5, 0), Currency: testCurrency},
+		{OperationType: models.WITHDRAW, WalletID: walletID, Amount: models.NewAmount(50, 0), Currency: testCurrency},
+		{OperationType: models.DEPOSIT, WalletID: walletID, Amount: models.NewAmount(1, 0), Currency: testCurrency},
+	}
+	mockRepo.On("GetWallets", mock.Anything).Return(map[uuid.UUID]*models.Wallet{walletID: {ID: walletID, Currency: testCurrency}}, nil)
+	mockRepo.On("ExecuteBatch", mock.Anything).Return(nil, &repository.BatchItemError{Index: 1, Err: apperrors.ErrInsufficientFunds})
+
+	result, err := service.ExecuteBatch(context.Background(), models.BatchRequest{Mode: models.BatchAtomic, Items: items})
+	if err != nil {
+		t.Fatalf("Expected the refusal in the result, got %v", err)
+	}
+	if result.Succeeded != 0 || result.Failed != 1 {
+		t.Errorf("Expected 0 succeeded and 1 failed items, got %d and %d", result.Succeeded, result.Failed)
+	}
+	expected := []string{models.BatchItemRolledBack, models.BatchItemFailed, models.BatchItemRolledBack}
+	for i, status := range expected {
+		if result.Results[i].Status != status {
+			t.Errorf("Expected item %d to be %s, got %s", i, status, result.Results[i].Status)
+		}
+	}
+	if result.Results[1].Error.Code != apperrors.CodeInsufficientFunds {
+		t.Errorf("Expected %s, got %s", apperrors.CodeInsufficientFunds, result.Results[1].Error.Code)
+	}
+}
+
+func TestWalletService_ExecuteBatch_AtomicInvalidItem(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	walletID, unknownID := uuid.New(), uuid.New()
+	mockRepo.On("GetWallets", mock.Anything).Return(map[uuid.UUID]*models.Wallet{walletID: {ID: walletID, Currency: testCurrency}}, nil)
+
+	tests := []struct {
+		item     models.BatchItem
+		expected apperrors.Code
+	}{
+		{models.BatchItem{OperationType: models.TRANSFER, FromWalletID: walletID, ToWalletID: walletID, Amount: models.NewAmount(5, 0), Currency: testCurrency}, apperrors.CodeSameWallet},
+		{models.BatchItem{OperationType: models.WITHDRAW, WalletID: unknownID, Amount: models.NewAmount(5, 0), Currency: testCurrency}, apperrors.CodeWalletNotFound},
+		{models.BatchItem{OperationType: models.TRANSFER, FromWalletID: walletID, ToWalletID: unknownID, Amount: models.NewAmount(5, 0), Currency: testCurrency}, apperrors.CodeDestinationWalletNotFound},
+	}
+
+	for _, tt := range tests {
+		result, err := service.ExecuteBatch(context.Background(), models.BatchRequest{Items: []models.BatchItem{
+			{OperationType: models.DEPOSIT, WalletID: walletID, Amount: models.NewAmount(5, 0), Currency: testCurrency},
+			tt.item,
+		}})
+		if err != nil {
+			t.Fatalf("Expected the refusal in the result, got %v", err)
+		}
+		if item := result.Results[1]; item.Status != models.BatchItemFailed || item.Error.Code != tt.expected {
+			t.Errorf("Expected item 1 to fail with %s, got %+v", tt.expected, item)
+		}
+	}
+
+	mockRepo.AssertNotCalled(t, "ExecuteBatch", mock.Anything)
+}
+
+func TestWalletService_ExecuteBatch_Invalid(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	transfer := models.BatchItem{OperationType: models.TRANSFER, FromWalletID: uuid.New(), ToWalletID: uuid.New(), Amount: models.NewAmount(1, 0), Currency: testCurrency}
+	depositOnly := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "payroll", Scopes: []auth.Scope{auth.ScopeWalletsWrite}, Roles: []auth.Role{auth.RoleAdmin}})
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		req      models.BatchRequest
+		expected error
+	}{
+		{"unknown mode", context.Background(), models.BatchRequest{Mode: "eventually", Items: []models.BatchItem{transfer}}, errInvalidBatchMode},
+		{"empty", context.Background(), models.BatchRequest{}, errBatchSize},
+		{"too large", context.Background(), models.BatchRequest{Items: make([]models.BatchItem, models.MaxBatchSize+1)}, errBatchSize},
+		{"transfer without scope", depositOnly, models.BatchRequest{Items: []models.BatchItem{transfer}}, apperrors.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		if _, err := service.ExecuteBatch(tt.ctx, tt.req); !errors.Is(err, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, err)
+		}
+	}
+
+	mockRepo.AssertNotCalled(t, "ExecuteBatch", mock.Anything)
+}
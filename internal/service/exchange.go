@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/exchange"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var errConvertedAmountTooSmall = apperrors.New(apperrors.CodeInvalidAmount, "converted amount rounds to zero")
+
+// CreateQuote prices a transfer between two wallets and locks the rate for the
+// configured quote TTL.
+func (s *WalletService) CreateQuote(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount models.Amount, currency models.Currency) (*models.Quote, error) {
+	if fromWalletID == toWalletID {
+		return nil, apperrors.ErrSameWallet
+	}
+
+	source, err := s.repo.GetWalletByID(ctx, fromWalletID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrWalletNotFound) {
+			return nil, apperrors.ErrSourceWalletNotFound
+		}
+		return nil, err
+	}
+	if err := authorizeWallet(ctx, source, apperrors.ErrSourceWalletNotFound); err != nil {
+		return nil, err
+	}
+	// Like a transfer, in the source wallet's currency if none is given
+	if currency == "" {
+		currency = source.Currency
+	}
+	currency, err = validateMoney(amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	if source.Currency != currency {
+		return nil, apperrors.ErrCurrencyMismatch
+	}
+
+	destination, err := s.getDestinationWallet(ctx, toWalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := s.convert(ctx, amount, currency, destination.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &models.Quote{
+		ID:                  uuid.New(),
+		FromWalletID:        fromWalletID,
+		ToWalletID:          toWalletID,
+		SourceAmount:        amount,
+		SourceCurrency:      currency,
+		DestinationAmount:   conversion.DestinationAmount,
+		DestinationCurrency: conversion.DestinationCurrency,
+		Rate:                conversion.Rate,
+		RoundingAdjustment:  conversion.RoundingAdjustment,
+		ExpiresAt:           time.Now().Add(s.quoteTTL),
+	}
+	if err := s.repo.CreateQuote(ctx, quote); err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+// conversionFor decides how the destination of a transfer is credited. It
+// returns nil for same-currency transfers without a quote.
+func (s *WalletService) conversionFor(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount models.Amount, currency models.Currency, quoteID *uuid.UUID) (*models.CurrencyConversion, error) {
+	if quoteID != nil {
+		quote, err := s.repo.GetQuote(ctx, *quoteID)
+		if err != nil {
+			return nil, err
+		}
+		if quote.FromWalletID != fromWalletID || quote.ToWalletID != toWalletID ||
+			quote.SourceAmount != amount || quote.SourceCurrency != currency {
+			return nil, apperrors.ErrQuoteMismatch
+		}
+		// Checked again when the quote is consumed, this only fails fast
+		if quote.UsedAt != nil || !time.Now().Before(quote.ExpiresAt) {
+			return nil, apperrors.ErrQuoteExpired
+		}
+		return quote.Conversion(), nil
+	}
+
+	destination, err := s.getDestinationWallet(ctx, toWalletID)
+	if err != nil {
+		return nil, err
+	}
+	if destination.Currency == currency {
+		return nil, nil
+	}
+
+	return s.convert(ctx, amount, currency, destination.Currency)
+}
+
+func (s *WalletService) convert(ctx context.Context, amount models.Amount, from, to models.Currency) (*models.CurrencyConversion, error) {
+	rate, err := s.rates.Rate(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := exchange.Convert(amount, rate, to)
+	if converted.Amount <= 0 {
+		return nil, errConvertedAmountTooSmall
+	}
+
+	return &models.CurrencyConversion{
+		DestinationAmount:   converted.Amount,
+		DestinationCurrency: to,
+		Rate:                exchange.FormatDecimal(rate),
+		RoundingAdjustment:  exchange.FormatDecimal(converted.Rounding),
+	}, nil
+}
+
+func (s *WalletService) getDestinationWallet(ctx context.Context, walletID uuid.UUID) (*models.Wallet, error) {
+	wallet, err := s.repo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrWalletNotFound) {
+			return nil, apperrors.ErrDestinationWalletNotFound
+		}
+		return nil, err
+	}
+	return wallet, nil
+}
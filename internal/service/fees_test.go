@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"wallet_service/internal/fees"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func testFeeSchedule(t *testing.T, revenueWalletID uuid.UUID) *fees.Schedule {
+	schedule, err := fees.NewSchedule(fees.Config{
+		RevenueWallets: map[models.Currency]uuid.UUID{testCurrency: revenueWalletID},
+		Rules: []fees.Rule{
+			{Operation: fees.Withdrawal, Percent: "1", Min: models.NewAmount(0, 25)},
+			{Operation: fees.Transfer, Flat: models.NewAmount(0, 10)},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build fee schedule: %v", err)
+	}
+	return schedule
+}
+
+func TestWalletService_PerformWalletOperation_ChargesWithdrawalFee(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	revenueWalletID := uuid.New()
+	service := NewWalletService(mockRepo, Options{Fees: testFeeSchedule(t, revenueWalletID)})
+
+	walletID := uuid.New()
+	amount := models.NewAmount(50, 0)
+	expectedFee := &models.Fee{Amount: models.NewAmount(0, 50), Currency: testCurrency, RevenueWalletID: revenueWalletID}
+	bookedFee := *expectedFee
+	bookedFee.Transaction = &models.Transaction{ID: uuid.New(), OperationType: models.FEE, Amount: expectedFee.Amount}
+
+	mockRepo.On("PerformOperation", walletID, models.WITHDRAW, amount, testCurrency, expectedFee).
+		Return(&models.Transaction{ID: uuid.New()}, nil).
+		Run(func(args mock.Arguments) { *args.Get(4).(*models.Fee) = bookedFee })
+	// Deposits are free
+	mockRepo.On("PerformOperation", walletID, models.DEPOSIT, amount, testCurrency, (*models.Fee)(nil)).
+		Return(&models.Transaction{ID: uuid.New()}, nil)
+
+	_, fee, err := service.PerformWalletOperation(context.Background(), walletID, models.WITHDRAW, amount, testCurrency)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if fee == nil || fee.Amount != expectedFee.Amount || fee.Transaction == nil {
+		t.Errorf("Expected a booked fee of %v, got %+v", expectedFee.Amount, fee)
+	}
+
+	if _, fee, err = service.PerformWalletOperation(context.Background(), walletID, models.DEPOSIT, amount, testCurrency); err != nil || fee != nil {
+		t.Errorf("Expected a free deposit, got fee %+v and error %v", fee, err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_Transfer_RevenueWalletPaysNoFee(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	revenueWalletID := uuid.New()
+	service := NewWalletService(mockRepo, Options{Fees: testFeeSchedule(t, revenueWalletID)})
+
+	toWalletID := uuid.New()
+	amount := models.NewAmount(50, 0)
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: testCurrency}, nil)
+	mockRepo.On("Transfer", revenueWalletID, toWalletID, amount, testCurrency, (*models.CurrencyConversion)(nil), (*models.Fee)(nil)).
+		Return(&models.Transfer{ID: uuid.New()}, nil)
+
+	if _, err := service.Transfer(context.Background(), revenueWalletID, toWalletID, amount, testCurrency, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/metrics"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// expireHoldsBatchSize bounds how many holds one sweep releases.
+const expireHoldsBatchSize = 100
+
+var errInvalidHoldExpiry = apperrors.New(apperrors.CodeInvalidRequest, "hold expiry is out of range")
+
+// CreateHold reserves amount on the wallet until it is captured, voided or
+// expires after ttl. A zero ttl uses the configured default.
+func (s *WalletService) CreateHold(ctx context.Context, walletID uuid.UUID, amount models.Amount, currency models.Currency, ttl time.Duration) (*models.Hold, error) {
+	currency, err := validateMoney(amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl == 0 {
+		ttl = s.holdTTL
+	}
+	if ttl < 0 || ttl > s.holdMaxTTL {
+		return nil, errInvalidHoldExpiry
+	}
+	if err := s.checkWalletAccess(ctx, walletID, apperrors.ErrWalletNotFound); err != nil {
+		return nil, err
+	}
+
+	hold := &models.Hold{
+		ID:        uuid.New(),
+		WalletID:  walletID,
+		Amount:    amount,
+		Currency:  currency,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	ctx, unlock, err := s.lockWallets(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	err = s.repo.CreateHold(ctx, hold)
+	logOutcome(ctx, "Hold", err, "wallet_id", walletID, "hold_id", hold.ID, "amount", amount, "currency", currency)
+	if err != nil {
+		observeRejection("HOLD", err)
+		return nil, err
+	}
+	return hold, nil
+}
+
+// GetHold returns the hold if the caller may act on its wallet. Holds on
+// other wallets are reported as not found.
+func (s *WalletService) GetHold(ctx context.Context, holdID uuid.UUID) (*models.Hold, error) {
+	hold, err := s.repo.GetHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkWalletAccess(ctx, hold.WalletID, apperrors.ErrHoldNotFound); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// CaptureHold debits amount of the hold from its wallet, or the whole hold
+// when amount is zero.
+func (s *WalletService) CaptureHold(ctx context.Context, holdID uuid.UUID, amount models.Amount) (*models.HoldCapture, error) {
+	if amount < 0 {
+		return nil, apperrors.ErrInvalidAmount
+	}
+
+	hold, err := s.GetHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if amount != 0 {
+		if err := hold.Currency.ValidateAmount(amount); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, unlock, err := s.lockWallets(ctx, hold.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	capture, err := s.repo.CaptureHold(ctx, holdID, amount)
+	logOutcome(ctx, "Hold capture", err, "wallet_id", hold.WalletID, "hold_id", holdID, "amount", amount)
+	if err != nil {
+		return nil, err
+	}
+	metrics.ObserveTransactions(*capture.Transaction)
+	return capture, nil
+}
+
+func (s *WalletService) VoidHold(ctx context.Context, holdID uuid.UUID) (*models.Hold, error) {
+	hold, err := s.GetHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, unlock, err := s.lockWallets(ctx, hold.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	voided, err := s.repo.VoidHold(ctx, holdID)
+	logOutcome(ctx, "Hold void", err, "wallet_id", hold.WalletID, "hold_id", holdID)
+	return voided, err
+}
+
+// ExpireHolds releases all holds whose expiry has passed.
+func (s *WalletService) ExpireHolds(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		expired, err := s.repo.ExpireHolds(ctx, expireHoldsBatchSize)
+		total += expired
+		if err != nil || expired < expireHoldsBatchSize {
+			return total, err
+		}
+	}
+}
+
+// RunHoldExpiry calls ExpireHolds every interval until ctx is done.
+func (s *WalletService) RunHoldExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.ExpireHolds(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to expire holds", "error", err)
+			}
+			if expired > 0 {
+				slog.InfoContext(ctx, "Released expired holds", "count", expired)
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// GetWalletLimits returns the limits that apply to the wallet and where they
+// come from.
+func (s *WalletService) GetWalletLimits(ctx context.Context, walletID uuid.UUID) (*models.WalletLimitsView, error) {
+	if err := s.checkWalletAccess(ctx, walletID, apperrors.ErrWalletNotFound); err != nil {
+		return nil, err
+	}
+	return s.repo.GetWalletLimits(ctx, walletID)
+}
+
+// SetWalletLimits assigns the wallet a tier and its own overrides. Only
+// admins may change limits, otherwise owners could lift their own.
+func (s *WalletService) SetWalletLimits(ctx context.Context, walletID uuid.UUID, req models.WalletLimitsRequest) (*models.WalletLimitsView, error) {
+	if principal := auth.PrincipalFrom(ctx); principal != nil && !principal.IsAdmin() {
+		return nil, apperrors.ErrForbidden
+	}
+
+	view, err := s.repo.SetWalletLimits(ctx, walletID, req.Tier, req.Overrides)
+	logOutcome(ctx, "Wallet limits change", err, "wallet_id", walletID, "tier", req.Tier)
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
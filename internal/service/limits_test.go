@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWalletService_SetWalletLimits_RequiresAdmin(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	_, err := service.SetWalletLimits(userContext("customer-1"), uuid.New(), models.WalletLimitsRequest{Tier: "premium"})
+	if !errors.Is(err, apperrors.ErrForbidden) {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "SetWalletLimits", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWalletService_SetWalletLimits(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	walletID := uuid.New()
+	req := models.WalletLimitsRequest{Tier: "premium"}
+	mockRepo.On("SetWalletLimits", walletID, "premium", models.WalletLimits{}).
+		Return(&models.WalletLimitsView{WalletID: walletID, Tier: "premium"}, nil)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "backoffice", Roles: []auth.Role{auth.RoleAdmin}})
+	if _, err := service.SetWalletLimits(ctx, walletID, req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
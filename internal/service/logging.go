@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+)
+
+// logOutcome logs a balance-affecting action at info level, or its failure at
+// warn level. The request ID is added from ctx by the logger.
+func logOutcome(ctx context.Context, action string, err error, attrs ...any) {
+	if err != nil {
+		slog.WarnContext(ctx, action+" failed", append(attrs, "error", err)...)
+		return
+	}
+	slog.InfoContext(ctx, action+" completed", attrs...)
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/metrics"
+	"wallet_service/internal/models"
+)
+
+const transferOutcomeOK = "ok"
+
+// observeRejection counts operations refused for lack of funds or because of
+// a limit.
+func observeRejection(operation string, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrInsufficientFunds):
+		metrics.InsufficientFunds.WithLabelValues(operation).Inc()
+	case apperrors.CodeOf(err) == apperrors.CodeLimitExceeded:
+		metrics.LimitRejections.WithLabelValues(operation).Inc()
+	}
+}
+
+func observeTransfer(transfer *models.Transfer, err error) {
+	if err != nil {
+		observeRejection(string(models.TRANSFER_OUT), err)
+		metrics.Transfers.WithLabelValues(strings.ToLower(string(apperrors.CodeOf(err)))).Inc()
+		return
+	}
+	metrics.Transfers.WithLabelValues(transferOutcomeOK).Inc()
+	metrics.ObserveTransactions(transfer.Transactions...)
+	if transfer.Fee != nil {
+		metrics.ObserveTransactions(*transfer.Fee.Transaction)
+	}
+}
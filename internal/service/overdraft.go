@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SetCreditLimit lets the wallet go up to creditLimit below zero, or turns
+// overdraft off when it is zero. Only admins may grant credit.
+func (s *WalletService) SetCreditLimit(ctx context.Context, walletID uuid.UUID, creditLimit models.Amount) (*models.Wallet, error) {
+	if creditLimit < 0 {
+		return nil, apperrors.ErrInvalidAmount
+	}
+	if principal := auth.PrincipalFrom(ctx); principal != nil && !principal.IsAdmin() {
+		return nil, apperrors.ErrForbidden
+	}
+
+	ctx, unlock, err := s.lockWallets(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	wallet, err := s.repo.SetCreditLimit(ctx, walletID, creditLimit)
+	logOutcome(ctx, "Credit limit change", err, "wallet_id", walletID, "credit_limit", creditLimit)
+	if err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// GetOverdraftPeriods returns the periods the wallet had a negative balance.
+func (s *WalletService) GetOverdraftPeriods(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftPeriod, error) {
+	if err := s.checkWalletAccess(ctx, walletID, apperrors.ErrWalletNotFound); err != nil {
+		return nil, err
+	}
+	return s.repo.ListOverdraftPeriods(ctx, walletID)
+}
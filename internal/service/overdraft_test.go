@@ -0,0 +1,24 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWalletService_SetCreditLimit_RequiresAdmin(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	_, err := service.SetCreditLimit(userContext("customer-1"), uuid.New(), models.NewAmount(100, 0))
+	if !errors.Is(err, apperrors.ErrForbidden) {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "SetCreditLimit", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/metrics"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ReverseTransaction refunds amount of a deposit, withdrawal, capture or
+// transfer, or all of what is left when amount is zero. Only admins may
+// reverse transactions, otherwise owners could undo their own withdrawals.
+func (s *WalletService) ReverseTransaction(ctx context.Context, transactionID uuid.UUID, amount models.Amount) (*models.Reversal, error) {
+	if amount < 0 {
+		return nil, apperrors.ErrInvalidAmount
+	}
+	if principal := auth.PrincipalFrom(ctx); principal != nil && !principal.IsAdmin() {
+		return nil, apperrors.ErrForbidden
+	}
+
+	original, err := s.repo.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := original.Currency.ValidateAmount(amount); err != nil {
+		return nil, err
+	}
+	walletIDs := []uuid.UUID{original.WalletID}
+	if original.OperationType == models.TRANSFER_OUT || original.OperationType == models.TRANSFER_IN {
+		walletIDs = append(walletIDs, *original.CounterpartyWalletID)
+	}
+
+	ctx, unlock, err := s.lockWallets(ctx, walletIDs...)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	reversal, err := s.repo.ReverseTransaction(ctx, transactionID, amount)
+	logOutcome(ctx, "Reversal", err, "transaction_id", transactionID, "amount", amount)
+	if err != nil {
+		observeRejection(string(models.REVERSAL), err)
+		return nil, err
+	}
+	metrics.ObserveTransactions(reversal.Transactions...)
+	return reversal, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWalletService_ReverseTransaction_RequiresAdmin(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	_, err := service.ReverseTransaction(userContext("customer-1"), uuid.New(), 0)
+	if !errors.Is(err, apperrors.ErrForbidden) {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "ReverseTransaction", mock.Anything, mock.Anything)
+}
+
+func TestWalletService_ReverseTransaction_Transfer(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	fromWalletID, toWalletID, transactionID := uuid.New(), uuid.New(), uuid.New()
+	amount := models.NewAmount(5, 0)
+	mockRepo.On("GetTransaction", transactionID).Return(&models.Transaction{
+		ID:                   transactionID,
+		WalletID:             fromWalletID,
+		OperationType:        models.TRANSFER_OUT,
+		Currency:             testCurrency,
+		CounterpartyWalletID: &toWalletID,
+	}, nil)
+	mockRepo.On("ReverseTransaction", transactionID, amount).Return(&models.Reversal{ID: uuid.New(), TransactionID: transactionID}, nil)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "backoffice", Roles: []auth.Role{auth.RoleAdmin}})
+	if _, err := service.ReverseTransaction(ctx, transactionID, amount); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
@@ -1,83 +1,301 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"sync"
+	"time"
 
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/exchange"
+	"wallet_service/internal/fees"
+	"wallet_service/internal/lock"
+	"wallet_service/internal/metrics"
 	"wallet_service/internal/models"
 	"wallet_service/internal/repository"
+	"wallet_service/internal/tracing"
 
 	"github.com/google/uuid"
 )
 
+const (
+	DefaultQuoteTTL   = 30 * time.Second
+	DefaultHoldTTL    = 7 * 24 * time.Hour
+	DefaultHoldMaxTTL = 30 * 24 * time.Hour
+)
+
+// Options holds the optional collaborators and settings of WalletService.
+// Zero values fall back to defaults suitable for tests and local runs.
+type Options struct {
+	// Locker serialises operations per wallet, in process by default
+	Locker lock.Locker
+	// Rates provides exchange rates for cross-currency transfers
+	Rates exchange.RateProvider
+	// QuoteTTL is how long a quoted exchange rate stays valid
+	QuoteTTL time.Duration
+	// HoldTTL is the expiry of holds created without an explicit one
+	HoldTTL time.Duration
+	// HoldMaxTTL is the longest expiry a hold may be created with
+	HoldMaxTTL time.Duration
+	// Fees prices withdrawals and transfers, which are free by default
+	Fees *fees.Schedule
+}
+
 type WalletService struct {
-	repo      repository.WalletRepositoryInterface
-	mu        sync.RWMutex
-	walletMUs map[uuid.UUID]*sync.Mutex
+	repo       repository.WalletRepositoryInterface
+	rates      exchange.RateProvider
+	quoteTTL   time.Duration
+	holdTTL    time.Duration
+	holdMaxTTL time.Duration
+	locker     lock.Locker
+	fees       *fees.Schedule
 }
 
-func NewWalletService(repo repository.WalletRepositoryInterface) *WalletService {
+func NewWalletService(repo repository.WalletRepositoryInterface, opts Options) *WalletService {
+	if opts.Locker == nil {
+		opts.Locker = lock.NewMemoryLocker()
+	}
+	if opts.Rates == nil {
+		opts.Rates, _ = exchange.NewStaticRateProvider(nil)
+	}
+	if opts.QuoteTTL <= 0 {
+		opts.QuoteTTL = DefaultQuoteTTL
+	}
+	if opts.HoldMaxTTL <= 0 {
+		opts.HoldMaxTTL = DefaultHoldMaxTTL
+	}
+	if opts.HoldTTL <= 0 || opts.HoldTTL > opts.HoldMaxTTL {
+		opts.HoldTTL = min(DefaultHoldTTL, opts.HoldMaxTTL)
+	}
+	if opts.Fees == nil {
+		opts.Fees, _ = fees.NewSchedule(fees.Config{})
+	}
+
 	return &WalletService{
-		repo:      repo,
-		walletMUs: make(map[uuid.UUID]*sync.Mutex),
+		repo:       repo,
+		rates:      opts.Rates,
+		quoteTTL:   opts.QuoteTTL,
+		holdTTL:    opts.HoldTTL,
+		holdMaxTTL: opts.HoldMaxTTL,
+		locker:     opts.Locker,
+		fees:       opts.Fees,
 	}
 }
 
-func (s *WalletService) getWalletMutex(walletID uuid.UUID) *sync.Mutex {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// lockWallets locks the wallets for the duration of an operation. The
+// operation must run with the returned context, which may carry locks to be
+// taken in its database transaction; the returned function releases them.
+func (s *WalletService) lockWallets(ctx context.Context, walletIDs ...uuid.UUID) (context.Context, func(), error) {
+	ids := make([]string, len(walletIDs))
+	for i, id := range walletIDs {
+		ids[i] = id.String()
+	}
+	// The operation's spans belong next to the lock span, not inside it
+	_, span := tracing.Start(ctx, "wallet.lock", tracing.WalletIDKey.StringSlice(ids))
 
-	if mu, exists := s.walletMUs[walletID]; exists {
-		return mu
+	start := time.Now()
+	ctx, unlock, err := s.locker.Lock(ctx, walletIDs...)
+	metrics.LockWait.Observe(time.Since(start).Seconds())
+	if err != nil {
+		err = fmt.Errorf("failed to lock wallets: %w", err)
+	}
+	tracing.End(span, err)
+	if err != nil {
+		return nil, nil, err
 	}
+	return ctx, unlock, nil
+}
 
-	mu := &sync.Mutex{}
-	s.walletMUs[walletID] = mu
-	return mu
+func (s *WalletService) GetWalletBalance(ctx context.Context, walletID uuid.UUID) (*models.Wallet, error) {
+	wallet, err := s.repo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeWallet(ctx, wallet, apperrors.ErrWalletNotFound); err != nil {
+		return nil, err
+	}
+	return wallet, nil
 }
 
-func (s *WalletService) GetWalletBalance(walletID uuid.UUID) (*models.Wallet, error) {
-	return s.repo.GetWalletByID(walletID)
+// ListWallets returns a page of wallets matching filter. Callers that are
+// not admins only ever see their own wallets.
+func (s *WalletService) ListWallets(ctx context.Context, filter models.WalletFilter) (*models.WalletPage, error) {
+	ownerID, err := walletOwner(ctx, filter.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	filter.OwnerID = ownerID
+	return s.repo.ListWallets(ctx, filter)
 }
 
-func (s *WalletService) PerformWalletOperation(walletID uuid.UUID, operationType models.OperationType, amount float64) error {
-	if amount <= 0 {
-		return fmt.Errorf("amount must be positive")
+// GetTransactions returns a page of the wallet ledger. The wallet is looked up
+// first so that an unknown wallet is reported instead of an empty page.
+func (s *WalletService) GetTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) (*models.TransactionPage, error) {
+	if _, err := s.GetWalletBalance(ctx, walletID); err != nil {
+		return nil, err
 	}
+	return s.repo.ListTransactions(ctx, walletID, filter)
+}
 
-	mu := s.getWalletMutex(walletID)
-	mu.Lock()
-	defer mu.Unlock()
+// PerformWalletOperation deposits to or withdraws from the wallet. The fee
+// charged on a withdrawal, if any, is returned along with the transaction.
+func (s *WalletService) PerformWalletOperation(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount models.Amount, currency models.Currency) (_ *models.Transaction, _ *models.Fee, err error) {
+	ctx, span := tracing.Start(ctx, "WalletService.PerformWalletOperation",
+		tracing.WalletIDKey.String(walletID.String()), tracing.OperationTypeKey.String(string(operationType)))
+	defer func() { tracing.End(span, err) }()
 
-	return s.repo.PerformOperation(walletID, operationType, amount)
-}
+	// An operation without a currency is in the wallet's own
+	if currency == "" {
+		if currency, err = s.walletCurrency(ctx, walletID, apperrors.ErrWalletNotFound); err != nil {
+			return nil, nil, err
+		}
+	} else if err := s.checkWalletAccess(ctx, walletID, apperrors.ErrWalletNotFound); err != nil {
+		return nil, nil, err
+	}
+	currency, err = validateMoney(amount, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fee *models.Fee
+	if operationType == models.WITHDRAW {
+		if fee, err = s.feeFor(fees.Withdrawal, walletID, amount, currency); err != nil {
+			return nil, nil, err
+		}
+	}
 
-func (s *WalletService) CreateWallet() (*models.Wallet, error) {
-	return s.repo.CreateWallet()
+	ctx, unlock, err := s.lockWallets(ctx, walletID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	transaction, err := s.repo.PerformOperation(ctx, walletID, operationType, amount, currency, fee)
+	logOutcome(ctx, "Wallet operation", err,
+		"wallet_id", walletID, "operation_type", operationType, "amount", amount, "currency", currency, "fee", feeAmount(fee))
+	if err != nil {
+		observeRejection(string(operationType), err)
+		return nil, nil, err
+	}
+	metrics.ObserveTransactions(*transaction)
+	if fee != nil {
+		metrics.ObserveTransactions(*fee.Transaction)
+	}
+	return transaction, fee, nil
 }
 
-func (s *WalletService) Transfer(fromWalletID, toWalletID uuid.UUID, amount float64) error {
-	if amount <= 0 {
-		return fmt.Errorf("amount must be positive")
+// CreateWallet opens a wallet for ownerID, see walletOwner for who may pass
+// which owner.
+func (s *WalletService) CreateWallet(ctx context.Context, currency models.Currency, ownerID *string) (*models.Wallet, error) {
+	currency, err := models.ParseCurrency(string(currency))
+	if err != nil {
+		return nil, err
 	}
+	ownerID, err = walletOwner(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.CreateWallet(ctx, currency, ownerID)
+}
+
+// Transfer debits amount in currency, by default the source wallet's own,
+// from the source wallet. When the
+// destination wallet holds another currency the amount is converted, either at
+// the rate locked by quoteID or at the current rate.
+func (s *WalletService) Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount models.Amount, currency models.Currency, quoteID *uuid.UUID) (transfer *models.Transfer, err error) {
+	ctx, span := tracing.Start(ctx, "WalletService.Transfer",
+		tracing.WalletIDKey.StringSlice([]string{fromWalletID.String(), toWalletID.String()}))
+	defer func() {
+		tracing.End(span, err)
+		observeTransfer(transfer, err)
+		logOutcome(ctx, "Transfer", err,
+			"from_wallet_id", fromWalletID, "to_wallet_id", toWalletID, "amount", amount, "currency", currency)
+	}()
 
 	if fromWalletID == toWalletID {
-		return fmt.Errorf("cannot transfer to the same wallet")
+		return nil, apperrors.ErrSameWallet
+	}
+	// Only the source must belong to the caller, money may be sent to anyone.
+	// A transfer without a currency is in the source wallet's own.
+	if currency == "" {
+		if currency, err = s.walletCurrency(ctx, fromWalletID, apperrors.ErrSourceWalletNotFound); err != nil {
+			return nil, err
+		}
+	} else if err := s.checkWalletAccess(ctx, fromWalletID, apperrors.ErrSourceWalletNotFound); err != nil {
+		return nil, err
+	}
+	currency, err = validateMoney(amount, currency)
+	if err != nil {
+		return nil, err
 	}
 
-	var firstMu, secondMu *sync.Mutex
-	if fromWalletID.String() < toWalletID.String() {
-		firstMu = s.getWalletMutex(fromWalletID)
-		secondMu = s.getWalletMutex(toWalletID)
-	} else {
-		firstMu = s.getWalletMutex(toWalletID)
-		secondMu = s.getWalletMutex(fromWalletID)
+	conversion, err := s.conversionFor(ctx, fromWalletID, toWalletID, amount, currency, quoteID)
+	if err != nil {
+		return nil, err
 	}
 
-	firstMu.Lock()
-	secondMu.Lock()
-	defer firstMu.Unlock()
-	defer secondMu.Unlock()
+	fee, err := s.feeFor(fees.Transfer, fromWalletID, amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, unlock, err := s.lockWallets(ctx, fromWalletID, toWalletID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	return s.repo.Transfer(fromWalletID, toWalletID, amount)
+	return s.repo.Transfer(ctx, fromWalletID, toWalletID, amount, currency, conversion, fee)
+}
+
+// feeFor prices an operation debiting walletID. The revenue wallets pay no
+// fees to themselves.
+func (s *WalletService) feeFor(operation fees.Operation, walletID uuid.UUID, amount models.Amount, currency models.Currency) (*models.Fee, error) {
+	fee, err := s.fees.Fee(operation, amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	if fee == nil || fee.RevenueWalletID == walletID {
+		return nil, nil
+	}
+	return fee, nil
+}
+
+func feeAmount(fee *models.Fee) models.Amount {
+	if fee == nil {
+		return 0
+	}
+	return fee.Amount
+}
+
+// validateMoney checks that amount is positive and representable in currency
+// and returns the normalized currency code.
+func validateMoney(amount models.Amount, currency models.Currency) (models.Currency, error) {
+	if amount <= 0 {
+		return "", apperrors.ErrInvalidAmount
+	}
+	currency, err := models.ParseCurrency(string(currency))
+	if err != nil {
+		return "", err
+	}
+	if err := currency.ValidateAmount(amount); err != nil {
+		return "", err
+	}
+	return currency, nil
+}
+
+// walletCurrency returns the currency of a wallet the principal of ctx may
+// access, or notFound.
+func (s *WalletService) walletCurrency(ctx context.Context, walletID uuid.UUID, notFound error) (models.Currency, error) {
+	wallet, err := s.repo.GetWalletByID(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrWalletNotFound) {
+			return "", notFound
+		}
+		return "", err
+	}
+	if err := authorizeWallet(ctx, wallet, notFound); err != nil {
+		return "", err
+	}
+	return wallet.Currency, nil
 }
@@ -1,64 +1,178 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/exchange"
 	"wallet_service/internal/models"
+	"wallet_service/internal/repository"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
+const testCurrency models.Currency = "USD"
+
 type MockWalletRepository struct {
 	mock.Mock
 }
 
-func (m *MockWalletRepository) CreateWallet() (*models.Wallet, error) {
-	args := m.Called()
+func (m *MockWalletRepository) CreateWallet(ctx context.Context, currency models.Currency, ownerID *string) (*models.Wallet, error) {
+	args := m.Called(currency, ownerID)
 	return args.Get(0).(*models.Wallet), args.Error(1)
 }
 
-func (m *MockWalletRepository) GetWalletByID(id uuid.UUID) (*models.Wallet, error) {
+func (m *MockWalletRepository) GetWalletByID(ctx context.Context, id uuid.UUID) (*models.Wallet, error) {
 	args := m.Called(id)
 	return args.Get(0).(*models.Wallet), args.Error(1)
 }
 
-func (m *MockWalletRepository) UpdateWalletBalance(id uuid.UUID, newBalance float64) error {
+func (m *MockWalletRepository) GetWallets(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.Wallet, error) {
+	args := m.Called(ids)
+	return args.Get(0).(map[uuid.UUID]*models.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) ListWallets(ctx context.Context, filter models.WalletFilter) (*models.WalletPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*models.WalletPage), args.Error(1)
+}
+
+func (m *MockWalletRepository) UpdateWalletBalance(ctx context.Context, id uuid.UUID, newBalance models.Amount) error {
 	args := m.Called(id, newBalance)
 	return args.Error(0)
 }
 
-func (m *MockWalletRepository) Deposit(walletID uuid.UUID, amount float64) error {
-	args := m.Called(walletID, amount)
-	return args.Error(0)
+func (m *MockWalletRepository) Deposit(ctx context.Context, walletID uuid.UUID, amount models.Amount, currency models.Currency) (*models.Transaction, error) {
+	args := m.Called(walletID, amount, currency)
+	return args.Get(0).(*models.Transaction), args.Error(1)
 }
 
-func (m *MockWalletRepository) Withdraw(walletID uuid.UUID, amount float64) error {
-	args := m.Called(walletID, amount)
-	return args.Error(0)
+func (m *MockWalletRepository) Withdraw(ctx context.Context, walletID uuid.UUID, amount models.Amount, currency models.Currency, fee *models.Fee) (*models.Transaction, error) {
+	args := m.Called(walletID, amount, currency, fee)
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepository) PerformOperation(ctx context.Context, walletID uuid.UUID, operationType models.OperationType, amount models.Amount, currency models.Currency, fee *models.Fee) (*models.Transaction, error) {
+	args := m.Called(walletID, operationType, amount, currency, fee)
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepository) Transfer(ctx context.Context, fromWalletID, toWalletID uuid.UUID, amount models.Amount, currency models.Currency, conversion *models.CurrencyConversion, fee *models.Fee) (*models.Transfer, error) {
+	args := m.Called(fromWalletID, toWalletID, amount, currency, conversion, fee)
+	return args.Get(0).(*models.Transfer), args.Error(1)
 }
 
-func (m *MockWalletRepository) PerformOperation(walletID uuid.UUID, operationType models.OperationType, amount float64) error {
-	args := m.Called(walletID, operationType, amount)
+func (m *MockWalletRepository) CreateQuote(ctx context.Context, quote *models.Quote) error {
+	args := m.Called(quote)
 	return args.Error(0)
 }
 
-func (m *MockWalletRepository) Transfer(fromWalletID, toWalletID uuid.UUID, amount float64) error {
-	args := m.Called(fromWalletID, toWalletID, amount)
+func (m *MockWalletRepository) GetQuote(ctx context.Context, id uuid.UUID) (*models.Quote, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Quote), args.Error(1)
+}
+
+func (m *MockWalletRepository) CreateHold(ctx context.Context, hold *models.Hold) error {
+	args := m.Called(hold)
 	return args.Error(0)
 }
 
+func (m *MockWalletRepository) GetHold(ctx context.Context, id uuid.UUID) (*models.Hold, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Hold), args.Error(1)
+}
+
+func (m *MockWalletRepository) CaptureHold(ctx context.Context, id uuid.UUID, amount models.Amount) (*models.HoldCapture, error) {
+	args := m.Called(id, amount)
+	return args.Get(0).(*models.HoldCapture), args.Error(1)
+}
+
+func (m *MockWalletRepository) VoidHold(ctx context.Context, id uuid.UUID) (*models.Hold, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Hold), args.Error(1)
+}
+
+func (m *MockWalletRepository) ExpireHolds(ctx context.Context, limit int) (int, error) {
+	args := m.Called(limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWalletRepository) ChangeWalletStatus(ctx context.Context, walletID uuid.UUID, status models.WalletStatus, reason, changedBy string, admin bool) (*models.WalletStatusChange, error) {
+	args := m.Called(walletID, status, reason, changedBy, admin)
+	return args.Get(0).(*models.WalletStatusChange), args.Error(1)
+}
+
+func (m *MockWalletRepository) ListWalletStatusChanges(ctx context.Context, walletID uuid.UUID) ([]models.WalletStatusChange, error) {
+	args := m.Called(walletID)
+	return args.Get(0).([]models.WalletStatusChange), args.Error(1)
+}
+
+func (m *MockWalletRepository) SetCreditLimit(ctx context.Context, walletID uuid.UUID, creditLimit models.Amount) (*models.Wallet, error) {
+	args := m.Called(walletID, creditLimit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) ListOverdraftPeriods(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftPeriod, error) {
+	args := m.Called(walletID)
+	return args.Get(0).([]models.OverdraftPeriod), args.Error(1)
+}
+
+func (m *MockWalletRepository) ExecuteBatch(ctx context.Context, ops []repository.BatchOperation) ([]models.BatchItemResult, error) {
+	args := m.Called(ops)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BatchItemResult), args.Error(1)
+}
+
+func (m *MockWalletRepository) GetWalletLimits(ctx context.Context, walletID uuid.UUID) (*models.WalletLimitsView, error) {
+	args := m.Called(walletID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WalletLimitsView), args.Error(1)
+}
+
+func (m *MockWalletRepository) SetWalletLimits(ctx context.Context, walletID uuid.UUID, tier string, overrides models.WalletLimits) (*models.WalletLimitsView, error) {
+	args := m.Called(walletID, tier, overrides)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WalletLimitsView), args.Error(1)
+}
+
+func (m *MockWalletRepository) GetTransaction(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockWalletRepository) ReverseTransaction(ctx context.Context, id uuid.UUID, amount models.Amount) (*models.Reversal, error) {
+	args := m.Called(id, amount)
+	return args.Get(0).(*models.Reversal), args.Error(1)
+}
+
+func (m *MockWalletRepository) ListTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) (*models.TransactionPage, error) {
+	args := m.Called(walletID, filter)
+	return args.Get(0).(*models.TransactionPage), args.Error(1)
+}
+
 func TestWalletService_GetWalletBalance(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	walletID := uuid.New()
-	expectedWallet := &models.Wallet{ID: walletID, Balance: 100.0}
+	expectedWallet := &models.Wallet{ID: walletID, Balance: models.NewAmount(100, 0)}
 
 	mockRepo.On("GetWalletByID", walletID).Return(expectedWallet, nil)
 
-	wallet, err := service.GetWalletBalance(walletID)
+	wallet, err := service.GetWalletBalance(context.Background(), walletID)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -76,50 +190,74 @@ func TestWalletService_GetWalletBalance(t *testing.T) {
 
 func TestWalletService_PerformWalletOperation_Deposit(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	walletID := uuid.New()
-	amount := 50.0
+	amount := models.NewAmount(50, 0)
+
+	expectedTransaction := &models.Transaction{ID: uuid.New(), WalletID: walletID, OperationType: models.DEPOSIT, Amount: amount}
 
-	mockRepo.On("PerformOperation", walletID, models.DEPOSIT, amount).Return(nil)
+	mockRepo.On("PerformOperation", walletID, models.DEPOSIT, amount, testCurrency, (*models.Fee)(nil)).Return(expectedTransaction, nil)
 
-	err := service.PerformWalletOperation(walletID, models.DEPOSIT, amount)
+	transaction, _, err := service.PerformWalletOperation(context.Background(), walletID, models.DEPOSIT, amount, testCurrency)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
+	if transaction.ID != expectedTransaction.ID {
+		t.Errorf("Expected transaction ID %v, got %v", expectedTransaction.ID, transaction.ID)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_PerformWalletOperation_WalletCurrency(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	walletID := uuid.New()
+	amount := models.NewAmount(50, 0)
+
+	mockRepo.On("GetWalletByID", walletID).Return(&models.Wallet{ID: walletID, Currency: "EUR"}, nil)
+	mockRepo.On("PerformOperation", walletID, models.DEPOSIT, amount, models.Currency("EUR"), (*models.Fee)(nil)).
+		Return(&models.Transaction{ID: uuid.New(), Currency: "EUR"}, nil)
+
+	if _, _, err := service.PerformWalletOperation(context.Background(), walletID, models.DEPOSIT, amount, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
 	mockRepo.AssertExpectations(t)
 }
 
 func TestWalletService_PerformWalletOperation_InvalidAmount(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	walletID := uuid.New()
-	amount := -50.0
+	amount := models.NewAmount(-50, 0)
 
-	err := service.PerformWalletOperation(walletID, models.DEPOSIT, amount)
+	_, _, err := service.PerformWalletOperation(context.Background(), walletID, models.DEPOSIT, amount, testCurrency)
 	if err == nil {
 		t.Fatal("Expected error for negative amount, got nil")
 	}
 
-	if err.Error() != "amount must be positive" {
-		t.Errorf("Expected error message 'amount must be positive', got '%v'", err.Error())
+	if !errors.Is(err, apperrors.ErrInvalidAmount) {
+		t.Errorf("Expected error 'amount must be positive', got '%v'", err)
 	}
 
-	mockRepo.AssertNotCalled(t, "PerformOperation", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "PerformOperation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestWalletService_PerformWalletOperation_Withdraw(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	walletID := uuid.New()
-	amount := 30.0
+	amount := models.NewAmount(30, 0)
 
-	mockRepo.On("PerformOperation", walletID, models.WITHDRAW, amount).Return(nil)
+	mockRepo.On("PerformOperation", walletID, models.WITHDRAW, amount, testCurrency, (*models.Fee)(nil)).Return(&models.Transaction{ID: uuid.New()}, nil)
 
-	err := service.PerformWalletOperation(walletID, models.WITHDRAW, amount)
+	_, _, err := service.PerformWalletOperation(context.Background(), walletID, models.WITHDRAW, amount, testCurrency)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -129,20 +267,20 @@ func TestWalletService_PerformWalletOperation_Withdraw(t *testing.T) {
 
 func TestWalletService_PerformWalletOperation_InsufficientFunds(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	walletID := uuid.New()
-	amount := 200.0
+	amount := models.NewAmount(200, 0)
 
-	mockRepo.On("PerformOperation", walletID, models.WITHDRAW, amount).Return(errors.New("insufficient funds"))
+	mockRepo.On("PerformOperation", walletID, models.WITHDRAW, amount, testCurrency, (*models.Fee)(nil)).Return((*models.Transaction)(nil), apperrors.ErrInsufficientFunds)
 
-	err := service.PerformWalletOperation(walletID, models.WITHDRAW, amount)
+	_, _, err := service.PerformWalletOperation(context.Background(), walletID, models.WITHDRAW, amount, testCurrency)
 	if err == nil {
 		t.Fatal("Expected error for insufficient funds, got nil")
 	}
 
-	if err.Error() != "insufficient funds" {
-		t.Errorf("Expected error message 'insufficient funds', got '%v'", err.Error())
+	if !errors.Is(err, apperrors.ErrInsufficientFunds) {
+		t.Errorf("Expected error 'insufficient funds', got '%v'", err)
 	}
 
 	mockRepo.AssertExpectations(t)
@@ -150,78 +288,108 @@ func TestWalletService_PerformWalletOperation_InsufficientFunds(t *testing.T) {
 
 func TestWalletService_Transfer_Success(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	fromWalletID := uuid.New()
 	toWalletID := uuid.New()
-	amount := 50.0
+	amount := models.NewAmount(50, 0)
+
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: testCurrency}, nil)
+
+	expectedTransfer := &models.Transfer{ID: uuid.New(), FromWalletID: fromWalletID, ToWalletID: toWalletID, Amount: amount}
 
-	mockRepo.On("Transfer", fromWalletID, toWalletID, amount).Return(nil)
+	mockRepo.On("Transfer", fromWalletID, toWalletID, amount, testCurrency, (*models.CurrencyConversion)(nil), (*models.Fee)(nil)).Return(expectedTransfer, nil)
 
-	err := service.Transfer(fromWalletID, toWalletID, amount)
+	transfer, err := service.Transfer(context.Background(), fromWalletID, toWalletID, amount, testCurrency, nil)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
+	if transfer.ID != expectedTransfer.ID {
+		t.Errorf("Expected transfer ID %v, got %v", expectedTransfer.ID, transfer.ID)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_Transfer_SourceCurrency(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	fromWalletID := uuid.New()
+	toWalletID := uuid.New()
+	amount := models.NewAmount(50, 0)
+
+	mockRepo.On("GetWalletByID", fromWalletID).Return(&models.Wallet{ID: fromWalletID, Currency: "EUR"}, nil)
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: "EUR"}, nil)
+	mockRepo.On("Transfer", fromWalletID, toWalletID, amount, models.Currency("EUR"), (*models.CurrencyConversion)(nil), (*models.Fee)(nil)).
+		Return(&models.Transfer{ID: uuid.New()}, nil)
+
+	if _, err := service.Transfer(context.Background(), fromWalletID, toWalletID, amount, "", nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
 	mockRepo.AssertExpectations(t)
 }
 
 func TestWalletService_Transfer_InvalidAmount(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	fromWalletID := uuid.New()
 	toWalletID := uuid.New()
-	amount := -50.0
+	amount := models.NewAmount(-50, 0)
 
-	err := service.Transfer(fromWalletID, toWalletID, amount)
+	_, err := service.Transfer(context.Background(), fromWalletID, toWalletID, amount, testCurrency, nil)
 	if err == nil {
 		t.Fatal("Expected error for negative amount, got nil")
 	}
 
-	if err.Error() != "amount must be positive" {
-		t.Errorf("Expected error message 'amount must be positive', got '%v'", err.Error())
+	if !errors.Is(err, apperrors.ErrInvalidAmount) {
+		t.Errorf("Expected error 'amount must be positive', got '%v'", err)
 	}
 
-	mockRepo.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestWalletService_Transfer_SameWallet(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	walletID := uuid.New()
-	amount := 50.0
+	amount := models.NewAmount(50, 0)
 
-	err := service.Transfer(walletID, walletID, amount)
+	_, err := service.Transfer(context.Background(), walletID, walletID, amount, testCurrency, nil)
 	if err == nil {
 		t.Fatal("Expected error for transferring to same wallet, got nil")
 	}
 
-	if err.Error() != "cannot transfer to the same wallet" {
-		t.Errorf("Expected error message 'cannot transfer to the same wallet', got '%v'", err.Error())
+	if !errors.Is(err, apperrors.ErrSameWallet) {
+		t.Errorf("Expected error 'cannot transfer to the same wallet', got '%v'", err)
 	}
 
-	mockRepo.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestWalletService_Transfer_InsufficientFunds(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	fromWalletID := uuid.New()
 	toWalletID := uuid.New()
-	amount := 200.0
+	amount := models.NewAmount(200, 0)
 
-	mockRepo.On("Transfer", fromWalletID, toWalletID, amount).Return(errors.New("insufficient funds"))
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: testCurrency}, nil)
 
-	err := service.Transfer(fromWalletID, toWalletID, amount)
+	mockRepo.On("Transfer", fromWalletID, toWalletID, amount, testCurrency, (*models.CurrencyConversion)(nil), (*models.Fee)(nil)).Return((*models.Transfer)(nil), apperrors.ErrInsufficientFunds)
+
+	_, err := service.Transfer(context.Background(), fromWalletID, toWalletID, amount, testCurrency, nil)
 	if err == nil {
 		t.Fatal("Expected error for insufficient funds, got nil")
 	}
 
-	if err.Error() != "insufficient funds" {
-		t.Errorf("Expected error message 'insufficient funds', got '%v'", err.Error())
+	if !errors.Is(err, apperrors.ErrInsufficientFunds) {
+		t.Errorf("Expected error 'insufficient funds', got '%v'", err)
 	}
 
 	mockRepo.AssertExpectations(t)
@@ -229,21 +397,23 @@ func TestWalletService_Transfer_InsufficientFunds(t *testing.T) {
 
 func TestWalletService_Transfer_SourceWalletNotFound(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	fromWalletID := uuid.New()
 	toWalletID := uuid.New()
-	amount := 50.0
+	amount := models.NewAmount(50, 0)
+
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: testCurrency}, nil)
 
-	mockRepo.On("Transfer", fromWalletID, toWalletID, amount).Return(errors.New("source wallet not found"))
+	mockRepo.On("Transfer", fromWalletID, toWalletID, amount, testCurrency, (*models.CurrencyConversion)(nil), (*models.Fee)(nil)).Return((*models.Transfer)(nil), apperrors.ErrSourceWalletNotFound)
 
-	err := service.Transfer(fromWalletID, toWalletID, amount)
+	_, err := service.Transfer(context.Background(), fromWalletID, toWalletID, amount, testCurrency, nil)
 	if err == nil {
 		t.Fatal("Expected error for source wallet not found, got nil")
 	}
 
-	if err.Error() != "source wallet not found" {
-		t.Errorf("Expected error message 'source wallet not found', got '%v'", err.Error())
+	if !errors.Is(err, apperrors.ErrSourceWalletNotFound) {
+		t.Errorf("Expected error 'source wallet not found', got '%v'", err)
 	}
 
 	mockRepo.AssertExpectations(t)
@@ -251,22 +421,315 @@ func TestWalletService_Transfer_SourceWalletNotFound(t *testing.T) {
 
 func TestWalletService_Transfer_DestinationWalletNotFound(t *testing.T) {
 	mockRepo := &MockWalletRepository{}
-	service := NewWalletService(mockRepo)
+	service := NewWalletService(mockRepo, Options{})
 
 	fromWalletID := uuid.New()
 	toWalletID := uuid.New()
-	amount := 50.0
+	amount := models.NewAmount(50, 0)
 
-	mockRepo.On("Transfer", fromWalletID, toWalletID, amount).Return(errors.New("destination wallet not found"))
+	mockRepo.On("GetWalletByID", toWalletID).Return((*models.Wallet)(nil), apperrors.ErrWalletNotFound)
 
-	err := service.Transfer(fromWalletID, toWalletID, amount)
+	_, err := service.Transfer(context.Background(), fromWalletID, toWalletID, amount, testCurrency, nil)
 	if err == nil {
 		t.Fatal("Expected error for destination wallet not found, got nil")
 	}
 
-	if err.Error() != "destination wallet not found" {
-		t.Errorf("Expected error message 'destination wallet not found', got '%v'", err.Error())
+	if !errors.Is(err, apperrors.ErrDestinationWalletNotFound) {
+		t.Errorf("Expected error 'destination wallet not found', got '%v'", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWalletService_GetTransactions(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	walletID := uuid.New()
+	filter := models.TransactionFilter{SortBy: models.SortByCreatedAt, Order: models.SortDesc, Limit: 10}
+	expectedPage := &models.TransactionPage{Transactions: []models.Transaction{{ID: uuid.New(), WalletID: walletID}}}
+
+	mockRepo.On("GetWalletByID", walletID).Return(&models.Wallet{ID: walletID}, nil)
+	mockRepo.On("ListTransactions", walletID, filter).Return(expectedPage, nil)
+
+	page, err := service.GetTransactions(context.Background(), walletID, filter)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(page.Transactions) != 1 {
+		t.Errorf("Expected 1 transaction, got %d", len(page.Transactions))
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_GetTransactions_WalletNotFound(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	walletID := uuid.New()
+
+	mockRepo.On("GetWalletByID", walletID).Return((*models.Wallet)(nil), apperrors.ErrWalletNotFound)
+
+	_, err := service.GetTransactions(context.Background(), walletID, models.TransactionFilter{})
+	if err == nil {
+		t.Fatal("Expected error for missing wallet, got nil")
+	}
+
+	mockRepo.AssertNotCalled(t, "ListTransactions", mock.Anything, mock.Anything)
+}
+
+func TestWalletService_CreateWallet(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	expectedWallet := &models.Wallet{ID: uuid.New(), Currency: "EUR"}
+
+	mockRepo.On("CreateWallet", models.Currency("EUR"), (*string)(nil)).Return(expectedWallet, nil)
+
+	wallet, err := service.CreateWallet(context.Background(), "eur", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if wallet.Currency != "EUR" {
+		t.Errorf("Expected currency EUR, got %v", wallet.Currency)
 	}
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestWalletService_CreateWallet_UnsupportedCurrency(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	_, err := service.CreateWallet(context.Background(), "XYZ", nil)
+	if !errors.Is(err, models.ErrUnsupportedCurrency) {
+		t.Errorf("Expected unsupported currency error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "CreateWallet", mock.Anything, mock.Anything)
+}
+
+func TestWalletService_PerformWalletOperation_CurrencyPrecision(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	walletID := uuid.New()
+
+	_, _, err := service.PerformWalletOperation(context.Background(), walletID, models.DEPOSIT, models.NewAmount(100, 50), "JPY")
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidAmount {
+		t.Errorf("Expected invalid amount error for fractional JPY, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "PerformOperation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func newRatesService(t *testing.T, repo *MockWalletRepository) *WalletService {
+	rates, err := exchange.NewStaticRateProvider(map[string]string{"USD/EUR": "0.9"})
+	if err != nil {
+		t.Fatalf("Failed to create rate provider: %v", err)
+	}
+	return NewWalletService(repo, Options{Rates: rates, QuoteTTL: time.Minute})
+}
+
+func TestWalletService_Transfer_CrossCurrency(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := newRatesService(t, mockRepo)
+
+	fromWalletID := uuid.New()
+	toWalletID := uuid.New()
+	amount := models.NewAmount(10, 5)
+
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: "EUR"}, nil)
+	expectedConversion := &models.CurrencyConversion{
+		DestinationAmount:   models.NewAmount(9, 5),
+		DestinationCurrency: "EUR",
+		Rate:                "0.9",
+		RoundingAdjustment:  "0.005",
+	}
+	mockRepo.On("Transfer", fromWalletID, toWalletID, amount, testCurrency, expectedConversion, (*models.Fee)(nil)).Return(&models.Transfer{ID: uuid.New()}, nil)
+
+	if _, err := service.Transfer(context.Background(), fromWalletID, toWalletID, amount, testCurrency, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_Transfer_RateUnavailable(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := newRatesService(t, mockRepo)
+
+	fromWalletID := uuid.New()
+	toWalletID := uuid.New()
+
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: "JPY"}, nil)
+
+	_, err := service.Transfer(context.Background(), fromWalletID, toWalletID, models.NewAmount(10, 0), testCurrency, nil)
+	if !errors.Is(err, exchange.ErrRateUnavailable) {
+		t.Errorf("Expected rate unavailable error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWalletService_CreateQuote(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := newRatesService(t, mockRepo)
+
+	fromWalletID := uuid.New()
+	toWalletID := uuid.New()
+
+	mockRepo.On("GetWalletByID", fromWalletID).Return(&models.Wallet{ID: fromWalletID, Currency: "EUR"}, nil)
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: "USD"}, nil)
+	mockRepo.On("CreateQuote", mock.AnythingOfType("*models.Quote")).Return(nil)
+
+	quote, err := service.CreateQuote(context.Background(), fromWalletID, toWalletID, models.NewAmount(9, 0), "EUR")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if quote.DestinationAmount != models.NewAmount(10, 0) || quote.DestinationCurrency != "USD" {
+		t.Errorf("Expected 10.00 USD, got %v %v", quote.DestinationAmount, quote.DestinationCurrency)
+	}
+
+	if !quote.ExpiresAt.After(time.Now()) {
+		t.Errorf("Expected quote to expire in the future, got %v", quote.ExpiresAt)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_CreateQuote_SourceCurrency(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := newRatesService(t, mockRepo)
+
+	fromWalletID := uuid.New()
+	toWalletID := uuid.New()
+
+	mockRepo.On("GetWalletByID", fromWalletID).Return(&models.Wallet{ID: fromWalletID, Currency: "EUR"}, nil)
+	mockRepo.On("GetWalletByID", toWalletID).Return(&models.Wallet{ID: toWalletID, Currency: "USD"}, nil)
+	mockRepo.On("CreateQuote", mock.AnythingOfType("*models.Quote")).Return(nil)
+
+	quote, err := service.CreateQuote(context.Background(), fromWalletID, toWalletID, models.NewAmount(9, 0), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if quote.SourceCurrency != "EUR" {
+		t.Errorf("Expected the quote in EUR, got %v", quote.SourceCurrency)
+	}
+}
+
+func TestWalletService_Transfer_ExpiredQuote(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := newRatesService(t, mockRepo)
+
+	fromWalletID := uuid.New()
+	toWalletID := uuid.New()
+	amount := models.NewAmount(10, 0)
+	quote := &models.Quote{
+		ID:             uuid.New(),
+		FromWalletID:   fromWalletID,
+		ToWalletID:     toWalletID,
+		SourceAmount:   amount,
+		SourceCurrency: testCurrency,
+		ExpiresAt:      time.Now().Add(-time.Second),
+	}
+
+	mockRepo.On("GetQuote", quote.ID).Return(quote, nil)
+
+	_, err := service.Transfer(context.Background(), fromWalletID, toWalletID, amount, testCurrency, &quote.ID)
+	if !errors.Is(err, apperrors.ErrQuoteExpired) {
+		t.Errorf("Expected quote expired error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWalletService_CreateHold(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{HoldTTL: time.Hour})
+
+	walletID := uuid.New()
+	mockRepo.On("CreateHold", mock.AnythingOfType("*models.Hold")).Return(nil)
+
+	hold, err := service.CreateHold(context.Background(), walletID, models.NewAmount(25, 0), "usd", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if hold.WalletID != walletID || hold.Currency != testCurrency {
+		t.Errorf("Unexpected hold %+v", hold)
+	}
+
+	if expiresIn := time.Until(hold.ExpiresAt); expiresIn <= 59*time.Minute || expiresIn > time.Hour {
+		t.Errorf("Expected hold to expire in an hour, got %v", expiresIn)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_CreateHold_ExpiryTooLong(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{HoldMaxTTL: time.Hour})
+
+	_, err := service.CreateHold(context.Background(), uuid.New(), models.NewAmount(25, 0), testCurrency, 2*time.Hour)
+	if apperrors.CodeOf(err) != apperrors.CodeInvalidRequest {
+		t.Errorf("Expected invalid request error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "CreateHold", mock.Anything)
+}
+
+func TestWalletService_CaptureHold(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	hold := &models.Hold{ID: uuid.New(), WalletID: uuid.New(), Amount: models.NewAmount(25, 0), Currency: testCurrency}
+	capture := &models.HoldCapture{Hold: hold, Transaction: &models.Transaction{OperationType: models.CAPTURE}}
+	mockRepo.On("GetHold", hold.ID).Return(hold, nil)
+	mockRepo.On("CaptureHold", hold.ID, models.NewAmount(10, 0)).Return(capture, nil)
+
+	result, err := service.CaptureHold(context.Background(), hold.ID, models.NewAmount(10, 0))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result != capture {
+		t.Errorf("Expected capture %+v, got %+v", capture, result)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWalletService_CaptureHold_NotFound(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	holdID := uuid.New()
+	mockRepo.On("GetHold", holdID).Return((*models.Hold)(nil), apperrors.ErrHoldNotFound)
+
+	_, err := service.CaptureHold(context.Background(), holdID, 0)
+	if !errors.Is(err, apperrors.ErrHoldNotFound) {
+		t.Errorf("Expected hold not found error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "CaptureHold", mock.Anything, mock.Anything)
+}
+
+func TestWalletService_PerformWalletOperation_ContextCanceled(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := service.PerformWalletOperation(ctx, uuid.New(), models.DEPOSIT, models.NewAmount(10, 0), testCurrency)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled error, got %v", err)
+	}
+
+	mockRepo.AssertNotCalled(t, "PerformOperation", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// systemActor is recorded as the author of status changes made without a
+// principal, e.g. by background jobs.
+const systemActor = "system"
+
+var errReasonRequired = apperrors.New(apperrors.CodeInvalidRequest, "reason is required")
+
+// FreezeWallet blocks debits from the wallet. Only admins may freeze wallets.
+func (s *WalletService) FreezeWallet(ctx context.Context, walletID uuid.UUID, reason string) (*models.WalletStatusChange, error) {
+	return s.changeWalletStatus(ctx, walletID, models.WalletFrozen, reason)
+}
+
+// UnfreezeWallet makes a frozen wallet active again. Only admins may unfreeze
+// wallets.
+func (s *WalletService) UnfreezeWallet(ctx context.Context, walletID uuid.UUID, reason string) (*models.WalletStatusChange, error) {
+	return s.changeWalletStatus(ctx, walletID, models.WalletActive, reason)
+}
+
+// CloseWallet closes an empty wallet for good. Owners may close their own
+// wallets unless they are frozen.
+func (s *WalletService) CloseWallet(ctx context.Context, walletID uuid.UUID, reason string) (*models.WalletStatusChange, error) {
+	return s.changeWalletStatus(ctx, walletID, models.WalletClosed, reason)
+}
+
+// GetWalletStatusChanges returns the status audit trail of the wallet.
+func (s *WalletService) GetWalletStatusChanges(ctx context.Context, walletID uuid.UUID) ([]models.WalletStatusChange, error) {
+	if err := s.checkWalletAccess(ctx, walletID, apperrors.ErrWalletNotFound); err != nil {
+		return nil, err
+	}
+	return s.repo.ListWalletStatusChanges(ctx, walletID)
+}
+
+func (s *WalletService) changeWalletStatus(ctx context.Context, walletID uuid.UUID, status models.WalletStatus, reason string) (*models.WalletStatusChange, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, errReasonRequired
+	}
+	if err := s.checkWalletAccess(ctx, walletID, apperrors.ErrWalletNotFound); err != nil {
+		return nil, err
+	}
+
+	changedBy, admin := systemActor, true
+	if principal := auth.PrincipalFrom(ctx); principal != nil {
+		// Otherwise owners could lift a freeze imposed on them, which is why
+		// the repository also keeps them from closing a frozen wallet
+		if status != models.WalletClosed && !principal.IsAdmin() {
+			return nil, apperrors.ErrForbidden
+		}
+		changedBy, admin = principal.ID, principal.IsAdmin()
+	}
+
+	ctx, unlock, err := s.lockWallets(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	change, err := s.repo.ChangeWalletStatus(ctx, walletID, status, reason, changedBy, admin)
+	logOutcome(ctx, "Wallet status change", err, "wallet_id", walletID, "status", status, "changed_by", changedBy, "reason", reason)
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"wallet_service/internal/apperrors"
+	"wallet_service/internal/auth"
+	"wallet_service/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWalletService_FreezeWallet_RequiresAdmin(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	owner := "customer-1"
+	walletID := uuid.New()
+	mockRepo.On("GetWalletByID", walletID).Return(&models.Wallet{ID: walletID, OwnerID: &owner}, nil)
+
+	// Owners cannot lift a freeze, nor impose one
+	_, err := service.UnfreezeWallet(userContext(owner), walletID, "please")
+	if !errors.Is(err, apperrors.ErrForbidden) {
+		t.Errorf("Expected forbidden error, got %v", err)
+	}
+
+	change := &models.WalletStatusChange{WalletID: walletID, FromStatus: models.WalletActive, ToStatus: models.WalletFrozen}
+	mockRepo.On("ChangeWalletStatus", walletID, models.WalletFrozen, "sanctions screening", "compliance", true).Return(change, nil)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "compliance", Roles: []auth.Role{auth.RoleAdmin}})
+	if _, err := service.FreezeWallet(ctx, walletID, "  sanctions screening "); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	mockRepo.AssertNumberOfCalls(t, "ChangeWalletStatus", 1)
+}
+
+func TestWalletService_CloseWallet(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	owner := "customer-1"
+	walletID := uuid.New()
+	mockRepo.On("GetWalletByID", walletID).Return(&models.Wallet{ID: walletID, OwnerID: &owner}, nil)
+
+	if _, err := service.CloseWallet(userContext(owner), walletID, " "); !errors.Is(err, errReasonRequired) {
+		t.Errorf("Expected reason required error, got %v", err)
+	}
+	mockRepo.AssertNotCalled(t, "ChangeWalletStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	change := &models.WalletStatusChange{WalletID: walletID, FromStatus: models.WalletActive, ToStatus: models.WalletClosed}
+	mockRepo.On("ChangeWalletStatus", walletID, models.WalletClosed, "moving abroad", "user:"+owner, false).Return(change, nil)
+
+	if _, err := service.CloseWallet(userContext(owner), walletID, "moving abroad"); err != nil {
+		t.Fatalf("Expected owner to close the wallet, got %v", err)
+	}
+
+	_, err := service.CloseWallet(userContext("customer-2"), walletID, "moving abroad")
+	if !errors.Is(err, apperrors.ErrWalletNotFound) {
+		t.Errorf("Expected wallet not found error, got %v", err)
+	}
+}
+
+func TestWalletService_CloseWallet_FrozenRequiresAdmin(t *testing.T) {
+	mockRepo := &MockWalletRepository{}
+	service := NewWalletService(mockRepo, Options{})
+
+	owner := "customer-1"
+	walletID := uuid.New()
+	mockRepo.On("GetWalletByID", walletID).Return(&models.Wallet{ID: walletID, OwnerID: &owner, Status: models.WalletFrozen}, nil)
+
+	// The repository refuses to close a frozen wallet for anyone but admins
+	mockRepo.On("ChangeWalletStatus", walletID, models.WalletClosed, "moving abroad", "user:"+owner, false).
+		Return((*models.WalletStatusChange)(nil), apperrors.ErrWalletFrozen)
+	if _, err := service.CloseWallet(userContext(owner), walletID, "moving abroad"); !errors.Is(err, apperrors.ErrWalletFrozen) {
+		t.Errorf("Expected wallet frozen error, got %v", err)
+	}
+
+	change := &models.WalletStatusChange{WalletID: walletID, FromStatus: models.WalletFrozen, ToStatus: models.WalletClosed}
+	mockRepo.On("ChangeWalletStatus", walletID, models.WalletClosed, "confirmed fraud", "compliance", true).Return(change, nil)
+
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ID: "compliance", Roles: []auth.Role{auth.RoleAdmin}})
+	if _, err := service.CloseWallet(ctx, walletID, "confirmed fraud"); err != nil {
+		t.Fatalf("Expected admin to close the frozen wallet, got %v", err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,105 @@
+// Package tracing sets up OpenTelemetry tracing. Spans are started with the
+// global tracer provider, so instrumented code costs next to nothing until
+// Setup installs an exporter.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+
+	serviceName = "wallet_service"
+)
+
+// Attribute keys shared by the spans of all layers.
+const (
+	WalletIDKey      = attribute.Key("wallet.id")
+	OperationTypeKey = attribute.Key("wallet.operation_type")
+)
+
+// Tracer returns the tracer of the service.
+func Tracer() trace.Tracer {
+	return otel.Tracer(serviceName)
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Setup installs the global tracer provider for the exporter, one of "none",
+// "stdout" or "file". The file exporter appends spans as JSON to path. The
+// returned function flushes the remaining spans and must be called on
+// shutdown.
+func Setup(exporter, path, version string) (func(context.Context) error, error) {
+	var w io.Writer
+	closeWriter := func() error { return nil }
+
+	switch exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		w = os.Stdout
+	case ExporterFile:
+		if path == "" {
+			return nil, fmt.Errorf("tracing file exporter requires a file path")
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		w, closeWriter = f, f.Close
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, expected %q, %q or %q", exporter, ExporterNone, ExporterStdout, ExporterFile)
+	}
+
+	spanExporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		closeWriter()
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	resource := sdkresource.NewSchemaless(
+		attribute.String("service.name", serviceName),
+		attribute.String("service.version", version),
+	)
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeErr := closeWriter(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,52 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup_FileExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(ExporterFile, path, "test")
+	require.NoError(t, err)
+
+	ctx, parent := Start(context.Background(), "parent", WalletIDKey.String("wallet-1"))
+	_, child := Start(ctx, "child", OperationTypeKey.String("DEPOSIT"))
+	End(child, errors.New("boom"))
+	End(parent, nil)
+
+	require.NoError(t, shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	out := string(data)
+	assert.Equal(t, 2, strings.Count(out, "\n"), "one JSON span per line")
+	assert.Contains(t, out, `"Name":"parent"`)
+	assert.Contains(t, out, `"Name":"child"`)
+	assert.Contains(t, out, `"wallet.id"`)
+	assert.Contains(t, out, `"wallet.operation_type"`)
+	assert.Contains(t, out, `"boom"`)
+}
+
+func TestSetup_Errors(t *testing.T) {
+	_, err := Setup("jaeger", "", "test")
+	assert.Error(t, err)
+
+	_, err = Setup(ExporterFile, "", "test")
+	assert.Error(t, err)
+
+	shutdown, err := Setup(ExporterNone, "", "test")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -1,27 +1,57 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"wallet_service/config"
+	"wallet_service/internal/logging"
 	"wallet_service/internal/server"
+	"wallet_service/internal/tracing"
 )
 
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fatal("Failed to load config", err)
 	}
 
+	logger, err := logging.New(os.Stdout, cfg.Log.Level)
+	if err != nil {
+		fatal("Failed to configure logging", err)
+	}
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(cfg.Tracing.Exporter, cfg.Tracing.File, server.Version)
+	if err != nil {
+		fatal("Failed to configure tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
 	server, err := server.NewServer(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize server: %v", err)
+		fatal("Failed to initialize server", err)
 	}
-	defer server.DB.Close()
 
-	addr := ":" + cfg.Server.Port
-	log.Printf("Server starting on %s", addr)
-	if err := server.Router.Run(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := server.Run(ctx); err != nil {
+		fatal("Server error", err)
 	}
 }
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
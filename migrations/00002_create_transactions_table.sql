@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE transactions (
+    id UUID PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    operation_type VARCHAR(32) NOT NULL,
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    balance_before DECIMAL(15,2) NOT NULL,
+    balance_after DECIMAL(15,2) NOT NULL,
+    counterparty_wallet_id UUID REFERENCES wallets(id),
+    reference_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_transactions_wallet_id_created_at ON transactions (wallet_id, created_at, id);
+CREATE INDEX idx_transactions_reference_id ON transactions (reference_id) WHERE reference_id IS NOT NULL;
+
+-- +goose StatementBegin
+CREATE FUNCTION transactions_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'transactions ledger is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER transactions_append_only
+    BEFORE UPDATE OR DELETE ON transactions
+    FOR EACH ROW EXECUTE FUNCTION transactions_append_only();
+
+-- +goose Down
+DROP TRIGGER transactions_append_only ON transactions;
+DROP FUNCTION transactions_append_only();
+DROP TABLE transactions;
@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX idx_transactions_wallet_id_amount ON transactions (wallet_id, amount, id);
+
+-- +goose Down
+DROP INDEX idx_transactions_wallet_id_amount;
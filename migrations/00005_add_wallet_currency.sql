@@ -0,0 +1,14 @@
+-- +goose Up
+-- Wallets created before multi-currency support were implicitly USD
+ALTER TABLE wallets ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE wallets ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE transactions ADD COLUMN currency CHAR(3);
+ALTER TABLE transactions DISABLE TRIGGER transactions_append_only;
+UPDATE transactions t SET currency = w.currency FROM wallets w WHERE t.wallet_id = w.id;
+ALTER TABLE transactions ENABLE TRIGGER transactions_append_only;
+ALTER TABLE transactions ALTER COLUMN currency SET NOT NULL;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN currency;
+ALTER TABLE wallets DROP COLUMN currency;
@@ -0,0 +1,29 @@
+-- +goose Up
+ALTER TABLE transactions
+    ADD COLUMN exchange_rate NUMERIC,
+    ADD COLUMN counterparty_amount DECIMAL(15,2),
+    ADD COLUMN counterparty_currency CHAR(3),
+    ADD COLUMN rounding_adjustment NUMERIC;
+
+CREATE TABLE fx_quotes (
+    id UUID PRIMARY KEY,
+    from_wallet_id UUID NOT NULL REFERENCES wallets(id),
+    to_wallet_id UUID NOT NULL REFERENCES wallets(id),
+    source_amount DECIMAL(15,2) NOT NULL CHECK (source_amount > 0),
+    source_currency CHAR(3) NOT NULL,
+    destination_amount DECIMAL(15,2) NOT NULL CHECK (destination_amount > 0),
+    destination_currency CHAR(3) NOT NULL,
+    rate NUMERIC NOT NULL CHECK (rate > 0),
+    rounding_adjustment NUMERIC NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE fx_quotes;
+ALTER TABLE transactions
+    DROP COLUMN exchange_rate,
+    DROP COLUMN counterparty_amount,
+    DROP COLUMN counterparty_currency,
+    DROP COLUMN rounding_adjustment;
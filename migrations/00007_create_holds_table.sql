@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN held_balance DECIMAL(15,2) NOT NULL DEFAULT 0.00 CHECK (held_balance >= 0);
+ALTER TABLE wallets ADD CONSTRAINT wallets_held_within_balance CHECK (balance - held_balance >= 0);
+
+CREATE TABLE holds (
+    id UUID PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    amount DECIMAL(15,2) NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    captured_amount DECIMAL(15,2) NOT NULL DEFAULT 0.00 CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    status VARCHAR(16) NOT NULL,
+    capture_transaction_id UUID REFERENCES transactions(id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_holds_wallet_id ON holds (wallet_id);
+CREATE INDEX idx_holds_active_expires_at ON holds (expires_at) WHERE status = 'ACTIVE';
+
+-- +goose Down
+DROP TABLE holds;
+ALTER TABLE wallets DROP CONSTRAINT wallets_held_within_balance;
+ALTER TABLE wallets DROP COLUMN held_balance;
@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN owner_id VARCHAR(255);
+CREATE INDEX idx_wallets_owner_id ON wallets(owner_id);
+
+ALTER TABLE api_keys
+    ADD COLUMN owner_id VARCHAR(255),
+    ADD COLUMN roles TEXT[] NOT NULL DEFAULT '{}';
+-- Keys issued so far could act on every wallet, keep it that way
+UPDATE api_keys SET roles = '{admin}';
+
+-- +goose Down
+ALTER TABLE api_keys
+    DROP COLUMN owner_id,
+    DROP COLUMN roles;
+DROP INDEX idx_wallets_owner_id;
+ALTER TABLE wallets DROP COLUMN owner_id;
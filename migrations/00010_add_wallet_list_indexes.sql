@@ -0,0 +1,14 @@
+-- +goose Up
+-- Listings filter by owner or currency and page by (sort column, id)
+DROP INDEX idx_wallets_owner_id;
+CREATE INDEX idx_wallets_owner_id_created_at ON wallets (owner_id, created_at, id);
+CREATE INDEX idx_wallets_currency_created_at ON wallets (currency, created_at, id);
+CREATE INDEX idx_wallets_created_at ON wallets (created_at, id);
+CREATE INDEX idx_wallets_balance ON wallets (balance, id);
+
+-- +goose Down
+DROP INDEX idx_wallets_balance;
+DROP INDEX idx_wallets_created_at;
+DROP INDEX idx_wallets_currency_created_at;
+DROP INDEX idx_wallets_owner_id_created_at;
+CREATE INDEX idx_wallets_owner_id ON wallets (owner_id);
@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE wallets ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'ACTIVE'
+    CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED'));
+CREATE INDEX idx_wallets_status_created_at ON wallets (status, created_at, id);
+
+-- Audit trail of freezes, unfreezes and closures, never updated or deleted
+CREATE TABLE wallet_status_changes (
+    id UUID PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    from_status VARCHAR(16) NOT NULL,
+    to_status VARCHAR(16) NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_wallet_status_changes_wallet_id ON wallet_status_changes (wallet_id, created_at);
+
+-- +goose Down
+DROP TABLE wallet_status_changes;
+DROP INDEX idx_wallets_status_created_at;
+ALTER TABLE wallets DROP COLUMN status;
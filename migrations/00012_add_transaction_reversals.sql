@@ -0,0 +1,10 @@
+-- +goose Up
+-- Compensating entries point at the entry they reverse; the amount left to
+-- reverse is the original amount minus the sum of its reversals
+ALTER TABLE transactions ADD COLUMN reversed_transaction_id UUID REFERENCES transactions(id);
+CREATE INDEX idx_transactions_reversed_transaction_id ON transactions (reversed_transaction_id)
+    WHERE reversed_transaction_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx_transactions_reversed_transaction_id;
+ALTER TABLE transactions DROP COLUMN reversed_transaction_id;
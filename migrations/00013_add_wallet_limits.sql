@@ -0,0 +1,9 @@
+-- +goose Up
+-- Limits come from the configured defaults and the wallet's tier, limits
+-- holds the per-wallet overrides, see models.WalletLimits
+ALTER TABLE wallets ADD COLUMN tier VARCHAR(32) NOT NULL DEFAULT 'standard';
+ALTER TABLE wallets ADD COLUMN limits JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE wallets DROP COLUMN limits;
+ALTER TABLE wallets DROP COLUMN tier;
@@ -0,0 +1,51 @@
+-- +goose Up
+-- A wallet may go as far below zero as its credit line, which replaces the
+-- non-negative balance and holds-within-balance checks
+ALTER TABLE wallets ADD COLUMN credit_limit DECIMAL(15,2) NOT NULL DEFAULT 0.00 CHECK (credit_limit >= 0);
+ALTER TABLE wallets DROP CONSTRAINT wallets_balance_check;
+ALTER TABLE wallets DROP CONSTRAINT wallets_held_within_balance;
+ALTER TABLE wallets ADD CONSTRAINT wallets_within_credit_limit CHECK (balance - held_balance >= -credit_limit);
+
+-- Periods a wallet spent with a negative balance; ended_at is NULL while the
+-- wallet is still overdrawn
+CREATE TABLE overdraft_periods (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMP WITH TIME ZONE,
+    lowest_balance DECIMAL(15,2) NOT NULL
+);
+
+CREATE INDEX idx_overdraft_periods_wallet_id ON overdraft_periods (wallet_id, started_at);
+CREATE UNIQUE INDEX idx_overdraft_periods_open ON overdraft_periods (wallet_id) WHERE ended_at IS NULL;
+
+-- Tracked by a trigger so that every way of changing a balance is covered
+-- +goose StatementBegin
+CREATE FUNCTION track_overdraft_periods() RETURNS trigger AS $$
+BEGIN
+    IF OLD.balance >= 0 AND NEW.balance < 0 THEN
+        INSERT INTO overdraft_periods (wallet_id, lowest_balance) VALUES (NEW.id, NEW.balance);
+    ELSIF OLD.balance < 0 AND NEW.balance >= 0 THEN
+        UPDATE overdraft_periods SET ended_at = NOW() WHERE wallet_id = NEW.id AND ended_at IS NULL;
+    ELSIF NEW.balance < 0 THEN
+        UPDATE overdraft_periods SET lowest_balance = LEAST(lowest_balance, NEW.balance)
+            WHERE wallet_id = NEW.id AND ended_at IS NULL;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER track_overdraft_periods
+    AFTER UPDATE OF balance ON wallets
+    FOR EACH ROW WHEN (OLD.balance IS DISTINCT FROM NEW.balance)
+    EXECUTE FUNCTION track_overdraft_periods();
+
+-- +goose Down
+DROP TRIGGER track_overdraft_periods ON wallets;
+DROP FUNCTION track_overdraft_periods();
+DROP TABLE overdraft_periods;
+ALTER TABLE wallets DROP CONSTRAINT wallets_within_credit_limit;
+ALTER TABLE wallets ADD CONSTRAINT wallets_held_within_balance CHECK (balance - held_balance >= 0);
+ALTER TABLE wallets ADD CONSTRAINT wallets_balance_check CHECK (balance >= 0);
+ALTER TABLE wallets DROP COLUMN credit_limit;
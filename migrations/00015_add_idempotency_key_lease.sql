@@ -0,0 +1,13 @@
+-- +goose Up
+-- A reservation still in progress when locked_until passes was abandoned, e.g.
+-- by a crashed instance, and may be taken over by a retry. Reservations left
+-- by earlier versions count as abandoned.
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+UPDATE idempotency_keys SET locked_until = created_at WHERE response_status IS NULL;
+
+-- Keys are deleted once they are older than the retention period
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys (created_at);
+
+-- +goose Down
+DROP INDEX idx_idempotency_keys_created_at;
+ALTER TABLE idempotency_keys DROP COLUMN locked_until;